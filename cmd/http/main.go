@@ -2,9 +2,14 @@ package main
 
 import (
 	"backends/config"
+	"backends/internal/models"
+	"backends/internal/repository"
 	"backends/internal/routes"
 	database "backends/internal/storage/databases"
+	"backends/internal/storage/query"
+	"backends/pkg/inflection"
 	"backends/pkg/shutdown"
+	"fmt"
 	"os"
 	"time"
 
@@ -13,21 +18,23 @@ import (
 )
 
 func buildServer(env config.EnvStructs) (*fiber.App, func(), error) {
-	db, cleanupDB, err := database.ConnectDatabase(
-		database.MySQL,
-		env.DB_HOST, env.DB_PORT, env.DB_USER, env.DB_PASSWORD, env.DB_DATABASE,
-		10*time.Second,
-	)
+	// query.DBClient writes MySQL flavoured SQL, the migration tool alone handles the other drivers
+	if conn, ok := env.Connection(config.DefaultConnection); ok && conn.Driver != string(database.MySQL) {
+		return nil, nil, fmt.Errorf("DB_DRIVER %q is only supported by the migration tool, the HTTP server needs mysql", conn.Driver)
+	}
 
+	registry, err := database.ConnectAll(env.Connections, 10*time.Second)
 	if err != nil {
 		golog.Warnf("Warning: Failed connection to database: %v\n", err)
-		db = nil
 	}
 
 	app := fiber.New()
 
-	if db != nil {
-		routes.SetupRoutes(app, db.GetSQLDB())
+	dbClient, err := query.NewDBClientFor(registry, config.DefaultConnection)
+	if err == nil {
+		dbClient.AllowModels(models.ModelRegistry...)
+		stores := repository.NewSQLStores(dbClient)
+		routes.SetupRoutes(app, stores)
 	} else {
 		golog.Info("Database not available, only error handler displayed.")
 		golog.Info("Please CTRL+C for shutdown!")
@@ -37,10 +44,7 @@ func buildServer(env config.EnvStructs) (*fiber.App, func(), error) {
 	}
 
 	cleanup := func() {
-		if cleanupDB != nil {
-			golog.Info("Closing database connection...")
-			cleanupDB()
-		}
+		registry.Close()
 	}
 
 	return app, cleanup, nil
@@ -76,6 +80,11 @@ func main() {
 		exitCode = 1
 		return
 	}
+	if err := inflection.Configure(env.INFLECTIONS); err != nil {
+		golog.Errorf("Error loading config: %v\n", err)
+		exitCode = 1
+		return
+	}
 
 	cleanup, err := run(env)
 	if err != nil {
@@ -1,17 +1,24 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
+	"html"
+	"io"
+	"os"
+	"regexp"
 	"strings"
+	"time"
 
 	"github.com/kataras/golog"
-	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 
 	constructmigrations "backends/cmd/migration/src"
 	"backends/config"
-	"backends/migrations"
+	"backends/internal/models"
+	"backends/internal/storage/query"
+	"backends/pkg/inflection"
 )
 
 type TableInfo struct {
@@ -21,113 +28,501 @@ type TableInfo struct {
 }
 
 type ColumnInfo struct {
-	Name string
-	Type string
-	Tag  string
+	Name     string
+	Type     string
+	Tag      string
+	DBType   string // column type as the database reports it, varchar(100)
+	Key      string // "PK", "FK", "UK" or a combination such as "PK, FK"
+	Nullable bool
 }
 
 type Relation struct {
 	RelatedTable string
 	ForeignKey   string
+	References   string
+	JoinTable    string // join table of a ManyToMany relation
 	RelationType string // "HasOne", "HasMany", "BelongsTo", "ManyToMany"
 }
 
 func getTables(db *gorm.DB) []TableInfo {
-	var tables []string
-	db.Raw("SHOW TABLES").Scan(&tables)
+	schemas, err := constructmigrations.LoadSchema(db)
+	if err != nil {
+		golog.Warnf("⚠️ Failed to read schema: %v", err)
+		return nil
+	}
+	relations := constructmigrations.InferRelations(schemas)
 
 	var tableInfos []TableInfo
 
-	for _, table := range tables {
-		columns := getColumns(db, table)
-		relations := getRelations(db, table)
-		tableInfos = append(tableInfos, TableInfo{Name: table, Columns: columns, Relations: relations})
+	for _, table := range schemas {
+		if err := query.ValidateIdentifier("table", table.Name); err != nil {
+			golog.Warnf("⚠️ Skipping table: %v", err)
+			continue
+		}
+		columns := getColumns(table)
+		tableInfos = append(tableInfos, TableInfo{Name: table.Name, Columns: columns, Relations: getRelations(relations[table.Name])})
 	}
 
 	return tableInfos
 }
 
-func getColumns(db *gorm.DB, tableName string) []ColumnInfo {
-	var columns []ColumnInfo
-	var result []struct {
-		Field   string `gorm:"column:Field"`
-		Type    string `gorm:"column:Type"`
-		Key     string `gorm:"column:Key"`
-		Null    string `gorm:"column:Null"`
-		Default string `gorm:"column:Default"`
+func getColumns(table constructmigrations.TableSchema) []ColumnInfo {
+	foreignKeys := map[string]bool{}
+	for _, key := range table.ForeignKeys {
+		for _, column := range key.Columns {
+			foreignKeys[column] = true
+		}
 	}
 
-	db.Raw(fmt.Sprintf("SHOW COLUMNS FROM %s", tableName)).Scan(&result)
-
-	for _, row := range result {
+	var columns []ColumnInfo
+	for _, column := range table.Columns {
 		colType := "string" // Default type
-		if strings.Contains(row.Type, "int") {
+		if strings.Contains(column.ColumnType, "int") {
 			colType = "int"
-		} else if strings.Contains(row.Type, "float") || strings.Contains(row.Type, "double") || strings.Contains(row.Type, "decimal") {
+		} else if strings.Contains(column.ColumnType, "float") || strings.Contains(column.ColumnType, "double") || strings.Contains(column.ColumnType, "decimal") {
 			colType = "float64"
-		} else if strings.Contains(row.Type, "bool") {
+		} else if strings.Contains(column.ColumnType, "bool") {
 			colType = "bool"
 		}
 
-		tag := fmt.Sprintf("gorm:\"column:%s\"", row.Field)
-		if row.Key == "PRI" {
+		var keys []string
+		tag := fmt.Sprintf("gorm:\"column:%s\"", column.Name)
+		if column.PrimaryKey {
+			keys = append(keys, "PK")
 			tag = "gorm:\"primaryKey\""
-		} else if row.Key == "MUL" {
-			tag = fmt.Sprintf("gorm:\"index;column:%s\"", row.Field)
+		}
+		if foreignKeys[column.Name] {
+			keys = append(keys, "FK")
+			if !column.PrimaryKey {
+				tag = fmt.Sprintf("gorm:\"index;column:%s\"", column.Name)
+			}
+		}
+		if column.Unique {
+			keys = append(keys, "UK")
 		}
 
-		columns = append(columns, ColumnInfo{Name: row.Field, Type: colType, Tag: tag})
+		columns = append(columns, ColumnInfo{
+			Name:     column.Name,
+			Type:     colType,
+			Tag:      tag,
+			DBType:   column.ColumnType,
+			Key:      strings.Join(keys, ", "),
+			Nullable: column.Nullable,
+		})
 	}
 
 	return columns
 }
 
-func getRelations(db *gorm.DB, tableName string) []Relation {
+func getRelations(tableRelations []constructmigrations.TableRelation) []Relation {
 	var relations []Relation
-	var result []struct {
-		Table            string `gorm:"column:TABLE_NAME"`
-		Column           string `gorm:"column:COLUMN_NAME"`
-		ReferencedTable  string `gorm:"column:REFERENCED_TABLE_NAME"`
-		ReferencedColumn string `gorm:"column:REFERENCED_COLUMN_NAME"`
+	for _, relation := range tableRelations {
+		foreignKey, references := relation.ForeignKey, relation.References
+		if relation.Type == constructmigrations.ManyToMany {
+			foreignKey, references = relation.JoinForeignKey, relation.ForeignKey
+		}
+		relations = append(relations, Relation{
+			RelatedTable: relation.RelatedTable,
+			ForeignKey:   foreignKey,
+			References:   references,
+			JoinTable:    relation.JoinTable,
+			RelationType: relation.Type,
+		})
 	}
 
-	query := `
-		SELECT TABLE_NAME, COLUMN_NAME, REFERENCED_TABLE_NAME, REFERENCED_COLUMN_NAME 
-		FROM INFORMATION_SCHEMA.KEY_COLUMN_USAGE 
-		WHERE TABLE_SCHEMA = DATABASE() 
-		AND TABLE_NAME = ? 
-		AND REFERENCED_TABLE_NAME IS NOT NULL;`
+	return relations
+}
 
-	db.Raw(query, tableName).Scan(&result)
+// erdEdge a foreign key drawn in the diagram, from the referencing table to the referenced one
+type erdEdge struct {
+	From, FromColumn string
+	To, ToColumn     string
+	Optional         bool // the foreign key column is nullable
+	Unique           bool // at most one row references each row of To
+}
 
-	for _, row := range result {
-		relationType := "BelongsTo"
-		relations = append(relations, Relation{
-			RelatedTable: row.ReferencedTable,
-			ForeignKey:   row.Column,
-			RelationType: relationType,
-		})
+// erdEdges one edge per foreign key column: the belongs-to side of every
+// relation, and both keys of each join table from the many-to-many sides
+func erdEdges(tables []TableInfo) []erdEdge {
+	columns := map[string]ColumnInfo{}
+	for _, table := range tables {
+		for _, column := range table.Columns {
+			columns[table.Name+"."+column.Name] = column
+		}
 	}
 
-	return relations
+	var edges []erdEdge
+	for _, table := range tables {
+		for _, relation := range table.Relations {
+			edge := erdEdge{From: table.Name, FromColumn: relation.ForeignKey, To: relation.RelatedTable, ToColumn: relation.References}
+			switch relation.RelationType {
+			case constructmigrations.BelongsTo:
+			case constructmigrations.ManyToMany:
+				edge.From, edge.To = relation.JoinTable, table.Name
+			default:
+				continue
+			}
+			column := columns[edge.From+"."+edge.FromColumn]
+			edge.Optional = column.Nullable
+			edge.Unique = strings.Contains(column.Key, "UK")
+			edges = append(edges, edge)
+		}
+	}
+	return edges
 }
 
-func runMigrations(dsn string) {
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
-	if err != nil {
-		golog.Fatal("Failed to connect to database:", err)
+// writeMermaid renders tables as a Mermaid erDiagram
+func writeMermaid(w io.Writer, tables []TableInfo) {
+	attribute := regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+	fmt.Fprintln(w, "erDiagram")
+	for _, table := range tables {
+		fmt.Fprintf(w, "    %s {\n", table.Name)
+		for _, column := range table.Columns {
+			dataType, _, _ := strings.Cut(column.DBType, "(")
+			dataType = strings.Trim(attribute.ReplaceAllString(dataType, "_"), "_")
+			line := fmt.Sprintf("        %s %s", dataType, column.Name)
+			if column.Key != "" {
+				line += " " + column.Key
+			}
+			if column.DBType != dataType {
+				line += fmt.Sprintf(" %q", strings.ReplaceAll(column.DBType, `"`, "'"))
+			}
+			fmt.Fprintln(w, line)
+		}
+		fmt.Fprintln(w, "    }")
 	}
 
-	for name, migration := range migrations.MigrationRegistry {
-		fmt.Println("🔄 Running migration:", name)
-		if err := migration(db); err != nil {
-			golog.Fatalf("❌ Migration failed: %s -> %v", name, err)
+	for _, edge := range erdEdges(tables) {
+		parent, child := "||", "o{"
+		if edge.Optional {
+			parent = "|o"
+		}
+		if edge.Unique {
+			child = "o|"
 		}
+		fmt.Fprintf(w, "    %s %s--%s %s : %q\n", edge.To, parent, child, edge.From, edge.FromColumn)
+	}
+}
+
+// writeDot renders tables as a Graphviz digraph, one HTML table per node and
+// an edge from each foreign key column to the column it references
+func writeDot(w io.Writer, tables []TableInfo) {
+	fmt.Fprintln(w, "digraph erd {")
+	fmt.Fprintln(w, "    graph [rankdir=LR];")
+	fmt.Fprintln(w, `    node [shape=plaintext, fontname="Helvetica"];`)
+	fmt.Fprintln(w, `    edge [fontname="Helvetica", fontsize=10, arrowhead=tee, arrowtail=crow, dir=both];`)
+	for _, table := range tables {
+		fmt.Fprintf(w, "    %q [label=<<table border=\"0\" cellborder=\"1\" cellspacing=\"0\">\n", table.Name)
+		fmt.Fprintf(w, "        <tr><td colspan=\"3\" bgcolor=\"lightgrey\"><b>%s</b></td></tr>\n", html.EscapeString(table.Name))
+		for _, column := range table.Columns {
+			fmt.Fprintf(w, "        <tr><td port=%q align=\"left\">%s</td><td align=\"left\">%s</td><td>%s</td></tr>\n",
+				column.Name, html.EscapeString(column.Name), html.EscapeString(column.DBType), column.Key)
+		}
+		fmt.Fprintln(w, "    </table>>];")
+	}
+
+	for _, edge := range erdEdges(tables) {
+		style := ""
+		if edge.Optional {
+			style = ", style=dashed"
+		}
+		fmt.Fprintf(w, "    %q:%q -> %q:%q [label=%q%s];\n", edge.From, edge.FromColumn, edge.To, edge.ToColumn, edge.FromColumn, style)
+	}
+	fmt.Fprintln(w, "}")
+}
+
+func exportERD(conn constructmigrations.Connection, format string, output string) error {
+	db, err := conn.Open()
+	if err != nil {
+		return fmt.Errorf("connecting to database: %w", err)
+	}
+
+	render := writeMermaid
+	switch format {
+	case "mermaid":
+	case "dot":
+		render = writeDot
+	default:
+		return fmt.Errorf("unknown ERD format %q, use mermaid or dot", format)
+	}
+
+	tables := getTables(db)
+	if output == "" {
+		render(os.Stdout, tables)
+		return nil
+	}
+
+	file, err := os.Create(output)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", output, err)
 	}
+	defer file.Close()
+
+	render(file, tables)
+	fmt.Printf("✅ ERD of %d table(s) written to %s\n", len(tables), output)
+	return nil
+}
 
-	fmt.Println("✅ All migrations applied successfully!")
-	constructmigrations.CreateModels(db)
+func runMigrations(conn constructmigrations.Connection, opts constructmigrations.RunOptions) error {
+	db, err := conn.Open()
+	if err != nil {
+		return fmt.Errorf("connecting to database: %w", err)
+	}
+
+	ran, err := constructmigrations.RunPending(db, opts)
+	if err != nil {
+		return fmt.Errorf("migration failed: %w", err)
+	}
+
+	if len(ran) == 0 {
+		fmt.Println("✅ Nothing to migrate, database is up to date.")
+	} else {
+		fmt.Printf("✅ Applied %d migration(s):\n", len(ran))
+		for _, name := range ran {
+			fmt.Println("   -", name)
+		}
+	}
+	if err := constructmigrations.CreateModels(db); err != nil {
+		return fmt.Errorf("generating models: %w", err)
+	}
 	constructmigrations.UpdateRegistryMigrations()
+	return nil
+}
+
+func dryRunMigrations(conn constructmigrations.Connection, opts constructmigrations.RunOptions, output string) error {
+	db, err := conn.Open()
+	if err != nil {
+		return fmt.Errorf("connecting to database: %w", err)
+	}
+
+	result, err := constructmigrations.DryRun(db, opts)
+	if err != nil {
+		return fmt.Errorf("dry run failed: %w", err)
+	}
+	if len(result) == 0 {
+		fmt.Println("✅ Nothing to migrate, database is up to date.")
+		return nil
+	}
+
+	if output == "" {
+		if err := constructmigrations.WriteDryRun(os.Stdout, result); err != nil {
+			return fmt.Errorf("failed to print SQL: %w", err)
+		}
+		return nil
+	}
+
+	file, err := os.Create(output)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", output, err)
+	}
+	defer file.Close()
+
+	if err := constructmigrations.WriteDryRun(file, result); err != nil {
+		return fmt.Errorf("failed to write %s: %w", output, err)
+	}
+	fmt.Printf("✅ SQL for %d pending migration(s) written to %s\n", len(result), output)
+	return nil
+}
+
+func runSeeders(conn constructmigrations.Connection, class string) error {
+	db, err := conn.Open()
+	if err != nil {
+		return fmt.Errorf("connecting to database: %w", err)
+	}
+
+	if class != "" {
+		class = constructmigrations.SeederName(class)
+	}
+	ran, err := constructmigrations.RunSeeders(db, class)
+	if err != nil {
+		return fmt.Errorf("seeding failed: %w", err)
+	}
+	fmt.Printf("✅ Ran %d seeder(s)\n", len(ran))
+	return nil
+}
+
+func rollbackMigrations(conn constructmigrations.Connection, steps int, all bool) error {
+	db, err := conn.Open()
+	if err != nil {
+		return fmt.Errorf("connecting to database: %w", err)
+	}
+
+	var reverted []string
+	if all {
+		reverted, err = constructmigrations.Reset(db)
+	} else {
+		reverted, err = constructmigrations.Rollback(db, steps)
+	}
+	if err != nil {
+		return fmt.Errorf("rollback failed: %w", err)
+	}
+
+	if len(reverted) == 0 {
+		fmt.Println("✅ Nothing to roll back.")
+		return nil
+	}
+	fmt.Printf("✅ Rolled back %d migration(s):\n", len(reverted))
+	for _, name := range reverted {
+		fmt.Println("   -", name)
+	}
+	return nil
+}
+
+func showStatus(conn constructmigrations.Connection) error {
+	db, err := conn.Open()
+	if err != nil {
+		return fmt.Errorf("connecting to database: %w", err)
+	}
+
+	statuses, err := constructmigrations.Status(db)
+	if err != nil {
+		return fmt.Errorf("failed to read migration status: %w", err)
+	}
+	constructmigrations.PrintStatus(os.Stdout, statuses)
+	return nil
+}
+
+func makeDiff(conn constructmigrations.Connection) error {
+	db, err := conn.Open()
+	if err != nil {
+		return fmt.Errorf("connecting to database: %w", err)
+	}
+
+	filename, diff, err := constructmigrations.CreateDiffMigration(db, models.ModelRegistry)
+	if err != nil {
+		return fmt.Errorf("failed to diff models against the schema: %w", err)
+	}
+	for _, table := range diff.Unmodeled {
+		fmt.Println("⚠️ Table", table, "has no model in models.ModelRegistry, leaving it untouched")
+	}
+	if diff.Empty() {
+		fmt.Println("✅ No differences between the models and the database schema.")
+		return nil
+	}
+
+	fmt.Printf("✅ Migration file created: %s (%d change(s))\n", filename, len(diff.Changes))
+	for _, change := range diff.Changes {
+		fmt.Println("   -", change)
+	}
+	return nil
+}
+
+func makeResource(conn constructmigrations.Connection, table string) error {
+	db, err := conn.Open()
+	if err != nil {
+		return fmt.Errorf("connecting to database: %w", err)
+	}
+
+	if err := constructmigrations.CreateResource(db, table); err != nil {
+		return fmt.Errorf("failed to scaffold %s: %w", table, err)
+	}
+	fmt.Println("✅ Resource scaffolded for table", table)
+	return nil
+}
+
+func squashMigrations(conn constructmigrations.Connection, before string) error {
+	db, err := conn.Open()
+	if err != nil {
+		return fmt.Errorf("connecting to database: %w", err)
+	}
+
+	baseline, squashed, err := constructmigrations.Squash(db, before)
+	if err != nil {
+		return fmt.Errorf("squash failed: %w", err)
+	}
+	fmt.Printf("✅ Squashed %d migration(s) into %s\n", len(squashed), baseline)
+	return nil
+}
+
+func dumpSchema(conn constructmigrations.Connection) error {
+	db, err := conn.Open()
+	if err != nil {
+		return fmt.Errorf("connecting to database: %w", err)
+	}
+
+	version, err := constructmigrations.DumpSchema(db, constructmigrations.SchemaDumpFile)
+	if err != nil {
+		return fmt.Errorf("schema dump failed: %w", err)
+	}
+	fmt.Printf("✅ Schema up to %s dumped to %s\n", version, constructmigrations.SchemaDumpFile)
+	return nil
+}
+
+// loadSchemaDump restores the schema dump when there is one, so only the
+// migrations newer than the dump are left to run
+func loadSchemaDump(conn constructmigrations.Connection, requireEmpty bool) error {
+	if _, err := os.Stat(constructmigrations.SchemaDumpFile); err != nil {
+		if requireEmpty {
+			return fmt.Errorf("no schema dump found at %s, create one with --action=schema:dump", constructmigrations.SchemaDumpFile)
+		}
+		return nil
+	}
+
+	db, err := conn.Open()
+	if err != nil {
+		return fmt.Errorf("connecting to database: %w", err)
+	}
+
+	if requireEmpty {
+		empty, err := constructmigrations.EmptyDatabase(db)
+		if err != nil {
+			return fmt.Errorf("failed to read the database tables: %w", err)
+		}
+		if !empty {
+			return errors.New("schema:load only runs on an empty database, use --action=fresh to rebuild an existing one")
+		}
+	}
+
+	version, err := constructmigrations.LoadSchemaDump(db, constructmigrations.SchemaDumpFile)
+	if err != nil {
+		return fmt.Errorf("schema load failed: %w", err)
+	}
+	fmt.Printf("📦 Loaded schema up to %s from %s\n", version, constructmigrations.SchemaDumpFile)
+	return nil
+}
+
+func backupDatabase(conn constructmigrations.Connection) {
+	db, err := conn.Open()
+	if err != nil {
+		golog.Fatal("Failed to connect to database:", err)
+	}
+
+	path, err := constructmigrations.BackupDatabase(db)
+	if err != nil {
+		golog.Fatalf("❌ Backup failed, nothing was dropped: %v", err)
+	}
+	fmt.Println("💾 Database backed up to", path)
+}
+
+// lockedActions actions that change the schema or data and must not run concurrently
+var lockedActions = map[string]bool{
+	"migrate":     true,
+	"rollback":    true,
+	"reset":       true,
+	"refresh":     true,
+	"fresh":       true,
+	"seed":        true,
+	"down":        true,
+	"down-all":    true,
+	"schema:load": true,
+	"squash":      true,
+}
+
+func acquireLock(conn constructmigrations.Connection, timeout time.Duration) func() {
+	db, err := conn.Open()
+	if err != nil {
+		golog.Fatal("Failed to connect to database:", err)
+	}
+
+	release, err := constructmigrations.AcquireMigrationLock(db, timeout)
+	if errors.Is(err, constructmigrations.ErrLockTimeout) {
+		golog.Fatalf("❌ Another migration process still holds the lock after %s, try again later or raise --lock-timeout", timeout)
+	}
+	if err != nil {
+		golog.Fatalf("❌ Failed to acquire migration lock: %v", err)
+	}
+	return release
 }
 
 func main() {
@@ -135,38 +530,145 @@ func main() {
 	if err != nil {
 		golog.Fatal("Failed to load config:", err)
 	}
+	if err := inflection.Configure(cfg.INFLECTIONS); err != nil {
+		golog.Fatal("Failed to load config:", err)
+	}
 
-	dsn := fmt.Sprintf(
-		"%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		cfg.DB_USER, cfg.DB_PASSWORD, cfg.DB_HOST, cfg.DB_PORT, cfg.DB_DATABASE,
-	)
+	conn, err := constructmigrations.NewConnection(cfg.DB_DRIVER, cfg.DB_HOST, cfg.DB_PORT, cfg.DB_USER, cfg.DB_PASSWORD, cfg.DB_DATABASE)
+	if err != nil {
+		golog.Fatal("Failed to load config:", err)
+	}
 
-	action := flag.String("action", "", "choose: migrate | create-migration | fresh")
-	tableName := flag.String("table", "", "table name for migration (only for create-migration)")
+	action := flag.String("action", "", "choose: migrate | status | rollback | reset | refresh | create-migration | make-diff | make:resource | erd | fresh | schema:dump | schema:load | squash | seed | create-seeder")
+	tableName := flag.String("table", "", "table name for migration (only for create-migration, make:resource and down)")
+	class := flag.String("class", "", "seeder to run or create, e.g. RolesSeeder (only for seed and create-seeder)")
+	seed := flag.Bool("seed", false, "run the seeders after migrating (only for fresh and refresh)")
+	sqlMigration := flag.Bool("sql", false, "scaffold .up.sql/.down.sql files instead of a Go migration (only for create-migration)")
+	step := flag.Int("step", 0, "number of migrations to roll back (only for rollback, default the last batch)")
+	dryRun := flag.Bool("dry-run", false, "print the SQL of pending migrations instead of applying them (only for migrate)")
+	output := flag.String("output", "", "file to write the dry-run SQL or the ERD to, default stdout")
+	before := flag.String("before", "", "squash the migrations up to and including this timestamp (only for squash)")
+	format := flag.String("format", "mermaid", "diagram format of erd: mermaid | dot")
+	force := flag.Bool("force", false, "run destructive actions without confirmation, required when GO_ENV=production")
+	backup := flag.Bool("backup", false, "dump schema and data to backups/ before a destructive action")
+	lockTimeout := flag.Duration("lock-timeout", time.Minute, "how long to wait for another migration process to release the lock")
+	allowOutOfOrder := flag.Bool("allow-out-of-order", false, "apply pending migrations older than the latest applied one")
 	flag.Parse()
 
+	runOpts := constructmigrations.RunOptions{AllowOutOfOrder: *allowOutOfOrder}
+
+	if err := constructmigrations.ConfirmDestructive(*action, cfg.GO_ENV, cfg.DB_DATABASE, *force, os.Stdin, os.Stdout); err != nil {
+		golog.Fatal("❌ ", err)
+	}
+	if *backup {
+		if _, destructive := constructmigrations.DestructiveActions[*action]; destructive {
+			backupDatabase(conn)
+		}
+	}
+
+	// golog.Fatal exits without running deferred calls, so the lock is released before any exit
+	release := func() {}
+	if lockedActions[*action] && !*dryRun {
+		release = acquireLock(conn, *lockTimeout)
+	}
+
 	switch *action {
 	case "migrate":
-		runMigrations(dsn)
+		if *dryRun {
+			err = dryRunMigrations(conn, runOpts, *output)
+			break
+		}
+		err = runMigrations(conn, runOpts)
+	case "status":
+		err = showStatus(conn)
+	case "rollback":
+		err = rollbackMigrations(conn, *step, false)
+	case "reset":
+		err = rollbackMigrations(conn, 0, true)
+	case "refresh":
+		err = rollbackMigrations(conn, 0, true)
+		if err == nil {
+			err = runMigrations(conn, runOpts)
+		}
+		if err == nil && *seed {
+			err = runSeeders(conn, "")
+		}
 	case "create-migration":
 		if *tableName == "" {
 			fmt.Println("Please provide a table name using --table=table_name")
-			return
+			break
+		}
+		if err = query.ValidateIdentifier("table", *tableName); err != nil {
+			break
+		}
+		if *sqlMigration {
+			constructmigrations.CreateSQLMigration(*tableName)
+			break
 		}
 		constructmigrations.CreateMigration(*tableName)
-		constructmigrations.UpdateRegistryMigrations()
+	case "make-diff":
+		err = makeDiff(conn)
+	case "make:resource":
+		if *tableName == "" {
+			fmt.Println("Please provide a table name using --table=table_name")
+			break
+		}
+		if err = query.ValidateIdentifier("table", *tableName); err != nil {
+			break
+		}
+		err = makeResource(conn, *tableName)
 	case "fresh":
-		constructmigrations.ResetDatabase(dsn)
-		runMigrations(dsn)
+		if err = constructmigrations.ResetDatabase(conn); err != nil {
+			err = fmt.Errorf("failed to reset database: %w", err)
+			break
+		}
+		err = loadSchemaDump(conn, false)
+		if err == nil {
+			err = runMigrations(conn, runOpts)
+		}
+		if err == nil && *seed {
+			err = runSeeders(conn, "")
+		}
+	case "erd":
+		err = exportERD(conn, *format, *output)
+	case "squash":
+		if *before == "" {
+			fmt.Println("Please provide a timestamp using --before=20250226160158")
+			break
+		}
+		err = squashMigrations(conn, *before)
+	case "schema:dump":
+		err = dumpSchema(conn)
+	case "schema:load":
+		err = loadSchemaDump(conn, true)
+		if err == nil {
+			err = runMigrations(conn, runOpts)
+		}
+	case "seed":
+		err = runSeeders(conn, *class)
+	case "create-seeder":
+		if *class == "" {
+			fmt.Println("Please provide a seeder name using --class=RolesSeeder")
+			break
+		}
+		constructmigrations.CreateSeeder(*class)
 	case "down":
 		if *tableName == "" {
 			fmt.Println("Please provide a table name using --table=table_name")
-			return
+			break
+		}
+		if err = query.ValidateIdentifier("table", *tableName); err != nil {
+			break
 		}
-		constructmigrations.DropTable(dsn, *tableName)
+		err = constructmigrations.DropTable(conn, *tableName)
 	case "down-all":
-		constructmigrations.DropAllTables(dsn)
+		err = constructmigrations.DropAllTables(conn)
 	default:
-		fmt.Println("Usage: go run main.go --action=[migrate|create-migration|fresh] [--table=table_name]")
+		fmt.Println("Usage: go run main.go --action=[migrate|status|rollback|reset|refresh|create-migration|make-diff|make:resource|erd|fresh|schema:dump|schema:load|squash|seed|create-seeder] [--table=table_name] [--step=N] [--dry-run [--output=file.sql]] [--format=mermaid|dot [--output=file]] [--before=timestamp] [--class=Seeder] [--seed] [--force] [--backup]")
+	}
+
+	release()
+	if err != nil {
+		golog.Fatal("❌ ", err)
 	}
 }
@@ -0,0 +1,56 @@
+package constructmigrations
+
+import (
+	"fmt"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// supported values of DB_DRIVER
+const (
+	MySQL    = "mysql"
+	Postgres = "postgres"
+	SQLite   = "sqlite"
+)
+
+// Connection the database the migration tool works on
+type Connection struct {
+	Driver string // mysql, postgres or sqlite
+	DSN    string
+}
+
+// NewConnection builds the DSN of driver from the DB_* settings, for sqlite
+// database is the path of the database file
+func NewConnection(driver, host, port, user, password, database string) (Connection, error) {
+	switch driver {
+	case "", MySQL:
+		return Connection{Driver: MySQL, DSN: fmt.Sprintf(
+			"%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+			user, password, host, port, database,
+		)}, nil
+	case Postgres:
+		return Connection{Driver: Postgres, DSN: fmt.Sprintf(
+			"host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+			host, port, user, password, database,
+		)}, nil
+	case SQLite:
+		return Connection{Driver: SQLite, DSN: database + "?_pragma=foreign_keys(1)"}, nil
+	}
+	return Connection{}, fmt.Errorf("unsupported DB_DRIVER %q, use mysql, postgres or sqlite", driver)
+}
+
+// Open connects GORM with the driver of the connection
+func (c Connection) Open() (*gorm.DB, error) {
+	switch c.Driver {
+	case MySQL:
+		return gorm.Open(mysql.Open(c.DSN), &gorm.Config{})
+	case Postgres:
+		return gorm.Open(postgres.Open(c.DSN), &gorm.Config{})
+	case SQLite:
+		return gorm.Open(sqlite.Open(c.DSN), &gorm.Config{})
+	}
+	return nil, fmt.Errorf("unsupported driver %q", c.Driver)
+}
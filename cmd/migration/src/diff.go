@@ -0,0 +1,570 @@
+package constructmigrations
+
+import (
+	"backends/pkg/inflection"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// diff steps, Up runs them in order and Down runs their inverse in reverse order
+const (
+	stepCreateTables = iota
+	stepColumns
+	stepDropForeignKeys
+	stepDropIndexes
+	stepDropColumns
+	stepCreateIndexes
+	stepAddForeignKeys
+	stepCount
+)
+
+// foreignKeyRules referential actions accepted in generated ADD CONSTRAINT statements
+var foreignKeyRules = map[string]bool{
+	"CASCADE":     true,
+	"SET NULL":    true,
+	"SET DEFAULT": true,
+	"RESTRICT":    true,
+	"NO ACTION":   true,
+}
+
+type localField struct {
+	Name string
+	Type string
+	Tag  string
+}
+
+type localType struct {
+	Name   string
+	Fields []localField
+}
+
+// migrationBody the local structs and statements of one generated migration function
+type migrationBody struct {
+	Types      []*localType
+	Statements []string
+	steps      [stepCount][]string
+}
+
+func (b *migrationBody) addField(typeName string, field localField) {
+	var t *localType
+	for _, existing := range b.Types {
+		if existing.Name == typeName {
+			t = existing
+		}
+	}
+	if t == nil {
+		t = &localType{Name: typeName}
+		b.Types = append(b.Types, t)
+	}
+	for _, existing := range t.Fields {
+		if existing.Name == field.Name {
+			return
+		}
+	}
+	t.Fields = append(t.Fields, field)
+}
+
+func (b *migrationBody) add(step int, statement string, args ...interface{}) {
+	b.steps[step] = append(b.steps[step], fmt.Sprintf(statement, args...))
+}
+
+// SchemaDiff the changes needed to bring the live schema in line with the models
+type SchemaDiff struct {
+	Up      migrationBody
+	Down    migrationBody
+	Changes []string
+	// Unmodeled tables present in the database without a model, left untouched
+	Unmodeled []string
+}
+
+// Empty true when the models already match the schema
+func (d *SchemaDiff) Empty() bool {
+	return len(d.Changes) == 0
+}
+
+func (d *SchemaDiff) change(format string, args ...interface{}) {
+	d.Changes = append(d.Changes, fmt.Sprintf(format, args...))
+}
+
+// DiffModels compares the given models with the live schema of db
+func DiffModels(db *gorm.DB, models []interface{}) (*SchemaDiff, error) {
+	diff := &SchemaDiff{}
+	cache := &sync.Map{}
+	mysql := db.Dialector.Name() == "mysql"
+
+	var schemas []*schema.Schema
+	modeled := map[string]bool{HistoryTable: true, LockTable: true}
+	expectedKeys := map[string][]*schema.Constraint{}
+	for _, model := range models {
+		s, err := schema.Parse(model, cache, db.NamingStrategy)
+		if err != nil {
+			return nil, fmt.Errorf("parsing model %T: %w", model, err)
+		}
+		schemas = append(schemas, s)
+		modeled[s.Table] = true
+	}
+	for _, s := range schemas {
+		for _, rel := range s.Relationships.Relations {
+			c := rel.ParseConstraint()
+			if c == nil || containsConstraint(expectedKeys[c.Schema.Table], c.Name) {
+				continue
+			}
+			expectedKeys[c.Schema.Table] = append(expectedKeys[c.Schema.Table], c)
+		}
+	}
+
+	for _, s := range schemas {
+		table, typeName := s.Table, s.Name
+
+		if !db.Migrator().HasTable(table) {
+			for _, field := range s.Fields {
+				if field.DBName != "" {
+					diff.Up.addField(typeName, modelField(db, field))
+				}
+			}
+			diff.Up.add(stepCreateTables, "db.Table(%q).Migrator().CreateTable(&%s{})", table, typeName)
+			diff.Down.add(stepCreateTables, "db.Migrator().DropTable(%q)", table)
+			diff.change("create table %s", table)
+
+			for _, c := range expectedKeys[table] {
+				if err := diff.addForeignKey(constraintKey(c), mysql); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+
+		columnTypes, err := db.Migrator().ColumnTypes(table)
+		if err != nil {
+			return nil, fmt.Errorf("reading columns of %s: %w", table, err)
+		}
+		live := map[string]gorm.ColumnType{}
+		for _, column := range columnTypes {
+			live[column.Name()] = column
+		}
+
+		for _, field := range s.Fields {
+			if field.DBName == "" {
+				continue
+			}
+			column, ok := live[field.DBName]
+			if !ok {
+				diff.Up.addField(typeName, modelField(db, field))
+				diff.Up.add(stepColumns, "db.Table(%q).Migrator().AddColumn(&%s{}, %q)", table, typeName, field.DBName)
+				diff.Down.add(stepColumns, "db.Migrator().DropColumn(%q, %q)", table, field.DBName)
+				diff.change("add column %s.%s", table, field.DBName)
+				continue
+			}
+
+			liveType, _ := column.ColumnType()
+			modelType := db.Dialector.DataTypeOf(field)
+			if declaresType(field) && normalizeType(liveType) != normalizeType(modelType) {
+				diff.Up.addField(typeName, modelField(db, field))
+				diff.Up.add(stepColumns, "db.Table(%q).Migrator().AlterColumn(&%s{}, %q)", table, typeName, field.DBName)
+				diff.Down.addField(typeName, liveField(column))
+				diff.Down.add(stepColumns, "db.Table(%q).Migrator().AlterColumn(&%s{}, %q)", table, typeName, field.DBName)
+				diff.change("change column %s.%s from %s to %s", table, field.DBName, liveType, modelType)
+			}
+		}
+
+		for _, column := range columnTypes {
+			if s.LookUpField(column.Name()) != nil {
+				continue
+			}
+			diff.Up.add(stepDropColumns, "db.Migrator().DropColumn(%q, %q)", table, column.Name())
+			diff.Down.addField(typeName, liveField(column))
+			diff.Down.add(stepDropColumns, "db.Table(%q).Migrator().AddColumn(&%s{}, %q)", table, typeName, column.Name())
+			diff.change("drop column %s.%s", table, column.Name())
+		}
+
+		liveKeys, err := ForeignKeys(db, table)
+		if err != nil {
+			return nil, err
+		}
+		if db.Dialector.Name() == SQLite {
+			nameSQLiteKeys(liveKeys, expectedKeys[table])
+		}
+		liveKeyNames := map[string]bool{}
+		for _, key := range liveKeys {
+			liveKeyNames[key.Name] = true
+			if !containsConstraint(expectedKeys[table], key.Name) {
+				if err := diff.dropForeignKey(key, mysql); err != nil {
+					return nil, err
+				}
+			}
+		}
+		for _, c := range expectedKeys[table] {
+			if !liveKeyNames[c.Name] {
+				if err := diff.addForeignKey(constraintKey(c), mysql); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		if err := diff.diffIndexes(db, s, liveKeyNames); err != nil {
+			return nil, err
+		}
+	}
+
+	tables, err := db.Migrator().GetTables()
+	if err != nil {
+		return nil, fmt.Errorf("listing tables: %w", err)
+	}
+	for _, table := range tables {
+		if !modeled[table] && !strings.HasPrefix(table, "sqlite_") {
+			diff.Unmodeled = append(diff.Unmodeled, table)
+		}
+	}
+
+	for step := 0; step < stepCount; step++ {
+		diff.Up.Statements = append(diff.Up.Statements, diff.Up.steps[step]...)
+		down := diff.Down.steps[stepCount-1-step]
+		for i := len(down) - 1; i >= 0; i-- {
+			diff.Down.Statements = append(diff.Down.Statements, down[i])
+		}
+	}
+	return diff, nil
+}
+
+func (d *SchemaDiff) diffIndexes(db *gorm.DB, s *schema.Schema, foreignKeyNames map[string]bool) error {
+	table, typeName := s.Table, s.Name
+
+	liveIndexes, err := db.Migrator().GetIndexes(table)
+	if err != nil {
+		return fmt.Errorf("reading indexes of %s: %w", table, err)
+	}
+	live := map[string]bool{}
+	for _, index := range liveIndexes {
+		live[index.Name()] = true
+	}
+
+	indexes := s.ParseIndexes()
+	uniques := s.ParseUniqueConstraints()
+
+	var names []string
+	for name := range indexes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if live[name] {
+			continue
+		}
+		for _, option := range indexes[name].Fields {
+			d.Up.addField(typeName, modelField(db, option.Field))
+		}
+		d.Up.add(stepCreateIndexes, "db.Table(%q).Migrator().CreateIndex(&%s{}, %q)", table, typeName, name)
+		d.Down.add(stepCreateIndexes, "db.Migrator().DropIndex(%q, %q)", table, name)
+		d.change("create index %s on %s", name, table)
+	}
+
+	names = names[:0]
+	for name := range uniques {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if live[name] || fieldHasUniqueIndex(liveIndexes, uniques[name].Field.DBName) {
+			continue
+		}
+		d.Up.addField(typeName, modelField(db, uniques[name].Field))
+		d.Up.add(stepCreateIndexes, "db.Table(%q).Migrator().CreateConstraint(&%s{}, %q)", table, typeName, name)
+		d.Down.addField(typeName, modelField(db, uniques[name].Field))
+		d.Down.add(stepCreateIndexes, "db.Table(%q).Migrator().DropConstraint(&%s{}, %q)", table, typeName, name)
+		d.change("add unique constraint %s on %s", name, table)
+	}
+
+	for _, index := range liveIndexes {
+		name := index.Name()
+		if primary, _ := index.PrimaryKey(); primary || name == "PRIMARY" || strings.HasPrefix(name, "sqlite_") {
+			continue
+		}
+		if _, ok := indexes[name]; ok {
+			continue
+		}
+		if _, ok := uniques[name]; ok {
+			continue
+		}
+		unique, _ := index.Unique()
+		if foreignKeyNames[name] || (unique && len(index.Columns()) == 1 && isUniqueField(uniques, index.Columns()[0])) {
+			continue
+		}
+
+		createIndex := "CREATE INDEX ? ON ? ?"
+		if unique {
+			createIndex = "CREATE UNIQUE INDEX ? ON ? ?"
+		}
+		d.Up.add(stepDropIndexes, "db.Migrator().DropIndex(%q, %q)", table, name)
+		d.Down.add(stepDropIndexes, "db.Exec(%q, clause.Column{Name: %q}, clause.Table{Name: %q}, %s).Error",
+			createIndex, name, table, columnsLiteral(index.Columns()))
+		d.change("drop index %s on %s", name, table)
+	}
+	return nil
+}
+
+func (d *SchemaDiff) addForeignKey(key ForeignKey, mysql bool) error {
+	add, err := addForeignKeyStatement(key)
+	if err != nil {
+		return err
+	}
+	d.Up.add(stepAddForeignKeys, "%s", add)
+	d.Down.add(stepAddForeignKeys, "%s", dropForeignKeyStatement(key, mysql))
+	d.change("add foreign key %s on %s", key.Name, key.Table)
+	return nil
+}
+
+func (d *SchemaDiff) dropForeignKey(key ForeignKey, mysql bool) error {
+	add, err := addForeignKeyStatement(key)
+	if err != nil {
+		return err
+	}
+	d.Up.add(stepDropForeignKeys, "%s", dropForeignKeyStatement(key, mysql))
+	d.Down.add(stepDropForeignKeys, "%s", add)
+	d.change("drop foreign key %s on %s", key.Name, key.Table)
+	return nil
+}
+
+func addForeignKeyStatement(key ForeignKey) (string, error) {
+	sql := "ALTER TABLE ? ADD CONSTRAINT ? FOREIGN KEY ? REFERENCES ? ?"
+	for _, rule := range []struct{ clause, action string }{{"ON DELETE", key.OnDelete}, {"ON UPDATE", key.OnUpdate}} {
+		if rule.action == "" {
+			continue
+		}
+		action := strings.ToUpper(strings.TrimSpace(rule.action))
+		if !foreignKeyRules[action] {
+			return "", fmt.Errorf("foreign key %s: unsupported %s action %q", key.Name, rule.clause, rule.action)
+		}
+		sql += " " + rule.clause + " " + action
+	}
+	return fmt.Sprintf("db.Exec(%q, clause.Table{Name: %q}, clause.Column{Name: %q}, %s, clause.Table{Name: %q}, %s).Error",
+		sql, key.Table, key.Name, columnsLiteral(key.Columns), key.ReferencedTable, columnsLiteral(key.ReferencedColumns)), nil
+}
+
+func dropForeignKeyStatement(key ForeignKey, mysql bool) string {
+	if mysql {
+		return fmt.Sprintf("db.Exec(%q, clause.Table{Name: %q}, clause.Column{Name: %q}).Error",
+			"ALTER TABLE ? DROP FOREIGN KEY ?", key.Table, key.Name)
+	}
+	return fmt.Sprintf("db.Migrator().DropConstraint(%q, %q)", key.Table, key.Name)
+}
+
+func constraintKey(c *schema.Constraint) ForeignKey {
+	key := ForeignKey{
+		Name:            c.Name,
+		Table:           c.Schema.Table,
+		ReferencedTable: c.ReferenceSchema.Table,
+		OnUpdate:        c.OnUpdate,
+		OnDelete:        c.OnDelete,
+	}
+	for _, field := range c.ForeignKeys {
+		key.Columns = append(key.Columns, field.DBName)
+	}
+	for _, field := range c.References {
+		key.ReferencedColumns = append(key.ReferencedColumns, field.DBName)
+	}
+	return key
+}
+
+// nameSQLiteKeys SQLite keeps no foreign key names, a live key takes the name
+// of the model constraint on the same columns and referenced table
+func nameSQLiteKeys(live []ForeignKey, expected []*schema.Constraint) {
+	for i := range live {
+		for _, c := range expected {
+			key := constraintKey(c)
+			if key.ReferencedTable == live[i].ReferencedTable && slices.Equal(key.Columns, live[i].Columns) {
+				live[i].Name = key.Name
+			}
+		}
+	}
+}
+
+func containsConstraint(constraints []*schema.Constraint, name string) bool {
+	for _, c := range constraints {
+		if c.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func isUniqueField(uniques map[string]schema.UniqueConstraint, column string) bool {
+	for _, unique := range uniques {
+		if unique.Field.DBName == column {
+			return true
+		}
+	}
+	return false
+}
+
+func fieldHasUniqueIndex(indexes []gorm.Index, column string) bool {
+	for _, index := range indexes {
+		unique, _ := index.Unique()
+		primary, _ := index.PrimaryKey()
+		if unique && !primary && len(index.Columns()) == 1 && index.Columns()[0] == column {
+			return true
+		}
+	}
+	return false
+}
+
+func columnsLiteral(columns []string) string {
+	var parts []string
+	for _, column := range columns {
+		parts = append(parts, fmt.Sprintf("{Name: %q}", column))
+	}
+	return "[]clause.Column{" + strings.Join(parts, ", ") + "}"
+}
+
+var intDisplayWidth = regexp.MustCompile(`\b((?:tiny|small|medium|big)?int)\(\d+\)`)
+
+// normalizeType a column type reduced to what matters when comparing model and database types
+func normalizeType(columnType string) string {
+	t := strings.ToLower(strings.TrimSpace(columnType))
+	for _, attribute := range []string{" auto_increment", " autoincrement", " primary key"} {
+		t = strings.ReplaceAll(t, attribute, "")
+	}
+	switch t {
+	case "boolean", "bool", "tinyint(1)":
+		return "boolean"
+	}
+	return intDisplayWidth.ReplaceAllString(t, "$1")
+}
+
+// declaresType true when the type of a field's column is given by its type or
+// size tag. Generated models leave both out, and the default type of their Go
+// type, text for a string, says nothing about the varchar it was read from.
+func declaresType(field *schema.Field) bool {
+	_, hasType := field.TagSettings["TYPE"]
+	_, hasSize := field.TagSettings["SIZE"]
+	return hasType || hasSize
+}
+
+// modelField the field of an inline migration struct reproducing a model field
+func modelField(db *gorm.DB, field *schema.Field) localField {
+	tag := field.Tag.Get("gorm")
+	if _, ok := field.TagSettings["COLUMN"]; !ok {
+		tag = "column:" + field.DBName + ";" + tag
+	}
+
+	goType, ok := goTypeName(field.FieldType)
+	if !ok {
+		goType = "string"
+		if _, ok := field.TagSettings["TYPE"]; !ok {
+			tag += ";type:" + db.Dialector.DataTypeOf(field)
+		}
+	}
+	return localField{Name: field.Name, Type: goType, Tag: strings.TrimSuffix(tag, ";")}
+}
+
+// liveField the field of an inline migration struct reproducing a database column
+func liveField(column gorm.ColumnType) localField {
+	columnType, _ := column.ColumnType()
+	tag := "column:" + column.Name() + ";type:" + columnType
+	if nullable, ok := column.Nullable(); ok && !nullable {
+		tag += ";not null"
+	}
+	if value, ok := column.DefaultValue(); ok && value != "" && !strings.ContainsAny(value, ";`\"") {
+		tag += ";default:" + value
+	}
+
+	goType := "string"
+	switch t := normalizeType(columnType); {
+	case t == "boolean":
+		goType = "bool"
+	case strings.Contains(t, "int"):
+		goType = "int64"
+	case strings.Contains(t, "float"), strings.Contains(t, "double"), strings.Contains(t, "decimal"), strings.Contains(t, "numeric"), strings.Contains(t, "real"):
+		goType = "float64"
+	case strings.Contains(t, "date"), strings.Contains(t, "time"):
+		goType = "time.Time"
+	}
+
+	return localField{Name: inflection.Camelize(column.Name()), Type: goType, Tag: tag}
+}
+
+// goTypeName how t is written inside a migration file, false when it is declared
+// in a package the migrations cannot import
+func goTypeName(t reflect.Type) (string, bool) {
+	switch t.Kind() {
+	case reflect.Ptr:
+		name, ok := goTypeName(t.Elem())
+		return "*" + name, ok
+	case reflect.Slice:
+		if t.Name() == "" {
+			name, ok := goTypeName(t.Elem())
+			return "[]" + name, ok
+		}
+	}
+
+	switch t.PkgPath() {
+	case "":
+		return t.String(), true
+	case "time", "database/sql", "gorm.io/gorm":
+		return t.String(), true
+	}
+	return "", false
+}
+
+// RenderDiffMigration the Go source of a migration applying diff
+func RenderDiffMigration(diff *SchemaDiff, upName, downName string) ([]byte, error) {
+	var source strings.Builder
+	for _, body := range []migrationBody{diff.Up, diff.Down} {
+		for _, t := range body.Types {
+			for _, field := range t.Fields {
+				source.WriteString(field.Type + " ")
+			}
+		}
+		source.WriteString(strings.Join(body.Statements, " "))
+	}
+
+	imports := []string{"gorm.io/gorm"}
+	for pkg, path := range map[string]string{"clause.": "gorm.io/gorm/clause", "time.": "time", "sql.": "database/sql"} {
+		if strings.Contains(source.String(), pkg) {
+			imports = append(imports, path)
+		}
+	}
+	sort.Strings(imports)
+
+	return renderGo("diff_migration", diffMigrationData{
+		Imports:  imports,
+		UpName:   upName,
+		DownName: downName,
+		Up:       diff.Up,
+		Down:     diff.Down,
+	})
+}
+
+// CreateDiffMigration writes a migration reconciling the live schema with models,
+// returns an empty path when there is nothing to change
+func CreateDiffMigration(db *gorm.DB, models []interface{}) (string, *SchemaDiff, error) {
+	diff, err := DiffModels(db, models)
+	if err != nil {
+		return "", nil, err
+	}
+	if diff.Empty() {
+		return "", diff, nil
+	}
+
+	timestamp := time.Now().Format("20060102150405")
+	content, err := RenderDiffMigration(diff, "Up"+timestamp+"SyncModels", "Down"+timestamp+"SyncModels")
+	if err != nil {
+		return "", nil, fmt.Errorf("rendering migration: %w", err)
+	}
+
+	filename := fmt.Sprintf("%s/%s_sync_models.go", migrationsDir, timestamp)
+	if err := os.WriteFile(filename, content, 0644); err != nil {
+		return "", nil, err
+	}
+	UpdateRegistryMigrations()
+	return filename, diff, nil
+}
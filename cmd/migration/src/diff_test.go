@@ -0,0 +1,51 @@
+package constructmigrations
+
+import (
+	"backends/internal/models"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestDiffAfterMigrateIsEmpty migrates a database, regenerates the models from
+// it and checks make-diff finds nothing to change
+func TestDiffAfterMigrateIsEmpty(t *testing.T) {
+	root, err := filepath.Abs(filepath.Join("..", "..", ".."))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, dir := chdirModuleCopy(t)
+	db := openTestDB(t)
+
+	if _, err := RunPending(db, RunOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := CreateModels(db); err != nil {
+		t.Fatal(err)
+	}
+
+	// the diff runs on the compiled models, they must be the ones just generated
+	files, err := filepath.Glob(filepath.Join(dir, "internal", "models", "*.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		generated, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		committed, err := os.ReadFile(filepath.Join(root, "internal", "models", filepath.Base(file)))
+		if err != nil || !bytes.Equal(generated, committed) {
+			t.Fatalf("internal/models/%s differs from the model generated after migrate", filepath.Base(file))
+		}
+	}
+
+	diff, err := DiffModels(db, models.ModelRegistry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.Empty() {
+		t.Errorf("diff right after migrate has changes:\n%v\nup:\n%v", diff.Changes, diff.Up.Statements)
+	}
+}
@@ -1,99 +1,115 @@
 package constructmigrations
 
 import (
-	"backends/migrations"
+	"backends/pkg/inflection"
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
-
-	"github.com/kataras/golog"
-	"golang.org/x/text/cases"
-	"golang.org/x/text/language"
-	"gorm.io/driver/mysql"
-	"gorm.io/gorm"
 )
 
-func DropAllTables(dsn string) {
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+func DropAllTables(conn Connection) error {
+	db, err := conn.Open()
 	if err != nil {
-		golog.Fatal("❌ Failed to connect to database:", err)
+		return fmt.Errorf("connecting to database: %w", err)
 	}
 
-	var tables []string
-	db.Raw("SHOW TABLES").Scan(&tables)
+	tables, err := userTables(db)
+	if err != nil {
+		return fmt.Errorf("listing tables: %w", err)
+	}
 
 	fmt.Println("⚠️ Dropping all tables...")
 	for _, table := range tables {
-		db.Migrator().DropTable(table)
+		if err := db.Migrator().DropTable(table); err != nil {
+			return fmt.Errorf("dropping table %s: %w", table, err)
+		}
 		fmt.Println("✅ Dropped table:", table)
 
-		DeleteModelFile(table)
+		if table != HistoryTable {
+			if err := DeleteModelFile(table); err != nil {
+				return err
+			}
+		}
 	}
 
-	updateModelRegistry()
+	if err := updateModelRegistry(); err != nil {
+		return err
+	}
 	fmt.Println("✅ All tables dropped successfully!")
+	return nil
 }
 
-func DropTable(dsn string, tableName string) {
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+func DropTable(conn Connection, tableName string) error {
+	db, err := conn.Open()
 	if err != nil {
-		golog.Fatal("❌ Failed to connect to database:", err)
+		return fmt.Errorf("connecting to database: %w", err)
 	}
 
-	if db.Migrator().HasTable(tableName) {
-		db.Migrator().DropTable(tableName)
-		DeleteModelFile(tableName)
+	if !db.Migrator().HasTable(tableName) {
+		fmt.Println("⚠️ Table not found:", tableName)
+		return nil
+	}
 
-		modelFiles, _ := filepath.Glob("internal/models/*.go")
-		if len(modelFiles) > 1 {
-			updateModelRegistry()
-		} else {
-			fmt.Println("⚠️ No models left to register. Registry cleared.")
-		}
+	if err := db.Migrator().DropTable(tableName); err != nil {
+		return fmt.Errorf("dropping table %s: %w", tableName, err)
+	}
+	if err := DeleteModelFile(tableName); err != nil {
+		return err
+	}
 
-		fmt.Println("✅ Dropped table:", tableName)
+	modelFiles, _ := filepath.Glob("internal/models/*.go")
+	if len(modelFiles) > 1 {
+		if err := updateModelRegistry(); err != nil {
+			return err
+		}
 	} else {
-		fmt.Println("⚠️ Table not found:", tableName)
+		fmt.Println("⚠️ No models left to register. Registry cleared.")
 	}
+
+	fmt.Println("✅ Dropped table:", tableName)
+	return nil
 }
 
-func DeleteModelFile(tableName string) {
+func DeleteModelFile(tableName string) error {
 	modelsDir := filepath.Join("internal", "models")
-	titleCase := cases.Title(language.English)
-	structName := titleCase.String(strings.ReplaceAll(tableName, "_", " "))
-	if strings.HasSuffix(structName, "s") {
-		structName = structName[:len(structName)-1]
-	}
+	structName := inflection.ModelName(tableName)
 
 	modelFilename := filepath.Join(modelsDir, structName+".go")
 
 	if _, err := os.Stat(modelFilename); os.IsNotExist(err) {
 		fmt.Println("⚠️ Model file not found:", modelFilename)
-		return
+		return nil
 	}
 
 	if err := os.Remove(modelFilename); err != nil {
-		fmt.Println("❌ Error deleting model file:", err)
-	} else {
-		updateModelRegistry()
-		fmt.Println("🗑️ Model file deleted:", modelFilename)
+		return fmt.Errorf("deleting model file: %w", err)
+	}
+	if err := updateModelRegistry(); err != nil {
+		return err
 	}
+	fmt.Println("🗑️ Model file deleted:", modelFilename)
+	return nil
 }
 
-func ResetDatabase(dsn string) {
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+// ResetDatabase drops every table, including the history table, but keeps the model files
+func ResetDatabase(conn Connection) error {
+	db, err := conn.Open()
 	if err != nil {
-		golog.Fatal("Failed to connect to database:", err)
+		return fmt.Errorf("connecting to database: %w", err)
+	}
+
+	tables, err := userTables(db)
+	if err != nil {
+		return fmt.Errorf("listing tables: %w", err)
 	}
 
 	fmt.Println("⚠️ Dropping all tables...")
-	for name := range migrations.MigrationRegistry {
-		tableName := ExtractTableName(name)
-		if tableName != "" {
-			db.Migrator().DropTable(tableName)
-			fmt.Println("✅ Dropped table:", tableName)
+	for _, table := range tables {
+		if err := db.Migrator().DropTable(table); err != nil {
+			return fmt.Errorf("dropping table %s: %w", table, err)
 		}
+		fmt.Println("✅ Dropped table:", table)
 	}
 	fmt.Println("✅ All tables dropped successfully!")
+	return nil
 }
@@ -0,0 +1,170 @@
+package constructmigrations
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// MigrationSQL statements a pending migration would execute
+type MigrationSQL struct {
+	Name       string
+	Statements []string
+}
+
+// sqlCapture logger that keeps the statements a dry run would execute.
+// Introspection queries and transaction control are left out.
+type sqlCapture struct {
+	logger.Interface
+	statements []string
+}
+
+func (c *sqlCapture) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	sql, _ := fc()
+	sql = strings.TrimSpace(sql)
+
+	switch statementKeyword(sql) {
+	case "", "SAVEPOINT", "RELEASE", "ROLLBACK":
+		return
+	}
+	if !isReadStatement(sql) {
+		c.statements = append(c.statements, strings.TrimSuffix(sql, ";"))
+	}
+}
+
+func statementKeyword(sql string) string {
+	return strings.ToUpper(strings.SplitN(strings.TrimSpace(sql), " ", 2)[0])
+}
+
+// isReadStatement true for the introspection queries migrators run, which a
+// dry run executes for real
+func isReadStatement(sql string) bool {
+	switch statementKeyword(sql) {
+	case "SELECT", "SHOW", "DESCRIBE", "DESC", "EXPLAIN", "WITH":
+		return true
+	case "PRAGMA":
+		return !strings.Contains(sql, "=")
+	}
+	return false
+}
+
+// dryRunPool runs reads on the real connection pool and drops everything
+// else, so migrators can introspect the live schema while nothing changes.
+// It does not hand out the *sql.DB, db.Connection would bypass it.
+// GORM's own DryRun mode returns no rows to introspection queries, which
+// makes HasTable and friends answer wrongly or, on SQLite, panic.
+type dryRunPool struct {
+	pool gorm.ConnPool
+}
+
+// noRows a query returning no rows, the result of a dropped RETURNING statement
+const noRows = "SELECT 1 WHERE 1 = 0"
+
+func (p *dryRunPool) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	if !isReadStatement(query) {
+		query = noRows
+	}
+	return p.pool.PrepareContext(ctx, query)
+}
+
+func (p *dryRunPool) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	if isReadStatement(query) {
+		return p.pool.ExecContext(ctx, query, args...)
+	}
+	return driver.RowsAffected(0), nil
+}
+
+func (p *dryRunPool) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	if !isReadStatement(query) {
+		return p.pool.QueryContext(ctx, noRows)
+	}
+	return p.pool.QueryContext(ctx, query, args...)
+}
+
+func (p *dryRunPool) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	if !isReadStatement(query) {
+		return p.pool.QueryRowContext(ctx, noRows)
+	}
+	return p.pool.QueryRowContext(ctx, query, args...)
+}
+
+// BeginTx migrators that rebuild tables run in a transaction, which has nothing to commit here
+func (p *dryRunPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	return p, nil
+}
+
+func (p *dryRunPool) Commit() error   { return nil }
+func (p *dryRunPool) Rollback() error { return nil }
+
+// isDryRun true for a GORM DryRun session and for the sessions DryRun hands to migrations
+func isDryRun(db *gorm.DB) bool {
+	_, ok := db.Statement.ConnPool.(*dryRunPool)
+	return db.DryRun || ok
+}
+
+// DryRun runs every pending migration on a connection that executes only
+// reads and returns the SQL it would execute, without applying it or
+// touching the history. Migrations see the live schema, not the result of
+// the pending migrations before them.
+func DryRun(db *gorm.DB, opts RunOptions) ([]MigrationSQL, error) {
+	pending, err := PendingMigrations(db, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []MigrationSQL
+	for _, migration := range pending {
+		capture := &sqlCapture{Interface: logger.Discard}
+		// a Context makes Session copy the statement, so the pool is not set on db
+		session := db.Session(&gorm.Session{Logger: capture, Context: context.Background()})
+		session.Statement.ConnPool = &dryRunPool{pool: db.Statement.ConnPool}
+
+		if err := silenceStdout(func() error { return migration.Up(session) }); err != nil {
+			return result, fmt.Errorf("%s: %w", migration.Name, err)
+		}
+		result = append(result, MigrationSQL{Name: migration.Name, Statements: capture.statements})
+	}
+
+	return result, nil
+}
+
+// silenceStdout keeps what migrations print out of the SQL written by WriteDryRun
+func silenceStdout(fn func() error) error {
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		return fn()
+	}
+	defer devNull.Close()
+
+	stdout := os.Stdout
+	os.Stdout = devNull
+	defer func() { os.Stdout = stdout }()
+
+	return fn()
+}
+
+func WriteDryRun(w io.Writer, result []MigrationSQL) error {
+	for _, migration := range result {
+		if _, err := fmt.Fprintf(w, "-- Migration: %s\n", migration.Name); err != nil {
+			return err
+		}
+		if len(migration.Statements) == 0 {
+			fmt.Fprintln(w, "-- (no statements)")
+		}
+		for _, statement := range migration.Statements {
+			if _, err := fmt.Fprintf(w, "%s;\n", statement); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintln(w)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package constructmigrations
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestDryRunRegistry dry-runs the registered migrations on a fresh and on a
+// partly migrated database and checks nothing is applied
+func TestDryRunRegistry(t *testing.T) {
+	sorted, err := SortedMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for applied := 0; applied < len(sorted); applied++ {
+		db := openTestDB(t)
+		if err := EnsureHistoryTable(db); err != nil {
+			t.Fatal(err)
+		}
+		for _, migration := range sorted[:applied] {
+			if err := migration.Up(db); err != nil {
+				t.Fatal(err)
+			}
+			if err := RecordMigration(db, migration.Name, 1, ""); err != nil {
+				t.Fatal(err)
+			}
+		}
+		before, err := userTables(db)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		result, err := DryRun(db, RunOptions{})
+		if err != nil {
+			t.Fatalf("%d applied: %v", applied, err)
+		}
+		if len(result) != len(sorted)-applied {
+			t.Fatalf("%d applied: dry run covers %d migrations, want %d", applied, len(result), len(sorted)-applied)
+		}
+		if len(result[0].Statements) == 0 || !strings.HasPrefix(result[0].Statements[0], "CREATE TABLE") {
+			t.Errorf("%d applied: %s would run %q, want a CREATE TABLE first", applied, result[0].Name, result[0].Statements)
+		}
+
+		after, err := userTables(db)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Join(after, ",") != strings.Join(before, ",") {
+			t.Errorf("%d applied: dry run changed the tables from %v to %v", applied, before, after)
+		}
+		pending, err := PendingMigrations(db, RunOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(pending) != len(sorted)-applied {
+			t.Errorf("%d applied: %d migrations pending after the dry run, want %d", applied, len(pending), len(sorted)-applied)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package constructmigrations
+
+import (
+	"backends/internal/storage/query"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DumpOptions controls what DumpDatabase writes
+type DumpOptions struct {
+	Data   bool     // write INSERT statements for every row
+	Tables []string // limit to these tables, default every table
+
+	ResetAutoIncrement bool // leave the AUTO_INCREMENT counters out of the DDL
+	SkipDrop           bool // no DROP TABLE IF EXISTS before each CREATE TABLE
+}
+
+var autoIncrementOption = regexp.MustCompile(` AUTO_INCREMENT=\d+`)
+
+// DumpDatabase writes the DDL of every table, and optionally its rows, as a
+// SQL script that can be replayed on an empty MySQL database
+func DumpDatabase(db *gorm.DB, w io.Writer, opts DumpOptions) error {
+	if db.Dialector.Name() != MySQL {
+		return fmt.Errorf("dumps rely on SHOW CREATE TABLE and are only supported for mysql, use the native dump tool of %s", db.Dialector.Name())
+	}
+
+	tables := opts.Tables
+	if len(tables) == 0 {
+		var err error
+		if tables, err = Tables(db); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(w, "-- Dump of %s generated at %s\n\n", db.Migrator().CurrentDatabase(), time.Now().Format(time.RFC3339))
+	fmt.Fprintln(w, "SET FOREIGN_KEY_CHECKS = 0;")
+	fmt.Fprintln(w)
+
+	for _, table := range tables {
+		if err := query.ValidateIdentifier("table", table); err != nil {
+			return err
+		}
+
+		var name, ddl string
+		row := db.Raw(fmt.Sprintf("SHOW CREATE TABLE %s", query.QuoteIdentifier(table))).Row()
+		if err := row.Scan(&name, &ddl); err != nil {
+			return fmt.Errorf("reading DDL of %s: %w", table, err)
+		}
+
+		if opts.ResetAutoIncrement {
+			ddl = autoIncrementOption.ReplaceAllString(ddl, "")
+		}
+
+		if !opts.SkipDrop {
+			fmt.Fprintf(w, "DROP TABLE IF EXISTS %s;\n", query.QuoteIdentifier(table))
+		}
+		fmt.Fprintf(w, "%s;\n\n", ddl)
+
+		if opts.Data {
+			if err := dumpRows(db, w, table); err != nil {
+				return fmt.Errorf("dumping rows of %s: %w", table, err)
+			}
+		}
+	}
+
+	_, err := fmt.Fprintln(w, "SET FOREIGN_KEY_CHECKS = 1;")
+	return err
+}
+
+func dumpRows(db *gorm.DB, w io.Writer, table string) error {
+	rows, err := db.Raw(fmt.Sprintf("SELECT * FROM %s", query.QuoteIdentifier(table))).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = query.QuoteIdentifier(column)
+	}
+	insert := fmt.Sprintf("INSERT INTO %s (%s) VALUES ", query.QuoteIdentifier(table), strings.Join(quoted, ", "))
+
+	values := make([]interface{}, len(columns))
+	for i := range values {
+		values[i] = new(interface{})
+	}
+
+	for rows.Next() {
+		if err := rows.Scan(values...); err != nil {
+			return err
+		}
+		literals := make([]string, len(values))
+		for i, value := range values {
+			literals[i] = sqlLiteral(*(value.(*interface{})))
+		}
+		if _, err := fmt.Fprintf(w, "%s(%s);\n", insert, strings.Join(literals, ", ")); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintln(w)
+	return rows.Err()
+}
+
+// sqlLiteral renders a scanned value as a MySQL literal
+func sqlLiteral(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case int64, int32, int, uint64, uint32, float64, float32:
+		return fmt.Sprint(v)
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	case time.Time:
+		return "'" + v.Format("2006-01-02 15:04:05.999999") + "'"
+	case []byte:
+		if isPrintable(v) {
+			return quoteString(string(v))
+		}
+		return "X'" + hex.EncodeToString(v) + "'"
+	case sql.RawBytes:
+		return sqlLiteral([]byte(v))
+	default:
+		return quoteString(fmt.Sprint(v))
+	}
+}
+
+func quoteString(s string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		`'`, `\'`,
+		"\x00", `\0`,
+		"\n", `\n`,
+		"\r", `\r`,
+		"\x1a", `\Z`,
+	)
+	return "'" + replacer.Replace(s) + "'"
+}
+
+func isPrintable(b []byte) bool {
+	for _, c := range b {
+		if c < 0x20 && c != '\n' && c != '\r' && c != '\t' {
+			return false
+		}
+	}
+	return true
+}
+
+// BackupDatabase dumps schema and data to backups/<database>_<timestamp>.sql
+// and returns the file path
+func BackupDatabase(db *gorm.DB) (string, error) {
+	if err := os.MkdirAll("backups", os.ModePerm); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join("backups", fmt.Sprintf("%s_%s.sql", db.Migrator().CurrentDatabase(), time.Now().Format("20060102150405")))
+	file, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	if err := DumpDatabase(db, file, DumpOptions{Data: true}); err != nil {
+		return "", err
+	}
+	return path, nil
+}
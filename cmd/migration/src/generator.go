@@ -1,9 +1,12 @@
 package constructmigrations
 
 import (
+	"backends/internal/storage/query"
+	"backends/pkg/inflection"
 	"fmt"
 	"os"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
@@ -13,177 +16,327 @@ import (
 	"gorm.io/gorm"
 )
 
-func CreateModelFile(db *gorm.DB, tableName string) {
-	titleCase := cases.Title(language.English)
-	structName := titleCase.String(strings.ReplaceAll(tableName, "_", " "))
-	structName = strings.ReplaceAll(structName, " ", "")
+func CreateModelFile(db *gorm.DB, tableName string) error {
+	if err := query.ValidateIdentifier("table", tableName); err != nil {
+		golog.Warnf("⚠️ Skipping model generation: %v", err)
+		return nil
+	}
 
-	if strings.HasSuffix(structName, "s") {
-		structName = structName[:len(structName)-1]
+	tables, err := LoadSchema(db)
+	if err != nil {
+		return fmt.Errorf("reading schema: %w", err)
+	}
+	relations := InferRelations(tables)
+	for _, table := range tables {
+		if table.Name == tableName {
+			return writeModelFile(table, relations[table.Name])
+		}
 	}
+	golog.Warnf("⚠️ Skipping model generation: table %s not found", tableName)
+	return nil
+}
+
+func writeModelFile(table TableSchema, relations []TableRelation) error {
+	structName := inflection.ModelName(table.Name)
 
 	modelsDir := "internal/models"
 	if _, err := os.Stat(modelsDir); os.IsNotExist(err) {
 		if err := os.MkdirAll(modelsDir, os.ModePerm); err != nil {
-			golog.Fatal("❌ Error creating models directory:", err)
+			return fmt.Errorf("creating models directory: %w", err)
 		}
 	}
 
 	modelFilename := fmt.Sprintf("%s/%s.go", modelsDir, structName)
-	file, err := os.Create(modelFilename)
+
+	source, err := modelSource(table, relations)
 	if err != nil {
-		golog.Fatal("❌ Error creating model file:", err)
-	}
-	defer file.Close()
-
-	columns := []struct {
-		Field   string
-		Type    string
-		Null    string
-		Key     string
-		Default string
-		Extra   string
-	}{}
-	db.Raw(fmt.Sprintf("SHOW COLUMNS FROM %s", tableName)).Scan(&columns)
-
-	foreignKeys := map[string]string{}
-	rows, _ := db.Raw(fmt.Sprintf("SHOW CREATE TABLE %s", tableName)).Rows()
-	defer rows.Close()
-	for rows.Next() {
-		var table, createStmt string
-		rows.Scan(&table, &createStmt)
-		re := regexp.MustCompile("CONSTRAINT .* FOREIGN KEY \\(`(.*?)`\\) REFERENCES `(.*?)` \\(`(.*?)`\\)")
-		matches := re.FindAllStringSubmatch(createStmt, -1)
-		for _, match := range matches {
-			if len(match) > 3 {
-				foreignKeys[match[1]] = match[2]
-			}
-		}
-	}
-
-	modelContent := fmt.Sprintf("package models\n\ntype %s struct {", structName)
-
-	for _, col := range columns {
-		fieldName := titleCase.String(strings.ReplaceAll(col.Field, "_", " "))
-		fieldName = strings.ReplaceAll(fieldName, " ", "")
-
-		colType := "string"
-		switch {
-		case strings.Contains(col.Type, "int"):
-			colType = "int"
-		case strings.Contains(col.Type, "bigint"):
-			colType = "int64"
-		case strings.Contains(col.Type, "float"), strings.Contains(col.Type, "double"), strings.Contains(col.Type, "decimal"):
-			colType = "float64"
-		case strings.Contains(col.Type, "datetime"), strings.Contains(col.Type, "timestamp"), strings.Contains(col.Type, "date"):
-			colType = "time.Time"
-		}
-
-		gormTag := fmt.Sprintf(`gorm:"column:%s"`, col.Field)
-		dbTag := fmt.Sprintf(`db:"%s"`, col.Field)
-		tags := fmt.Sprintf("`%s %s`", dbTag, gormTag)
-
-		if col.Key == "PRI" {
-			tags = fmt.Sprintf("`db:\"%s\" gorm:\"primaryKey;column:%s\"`", col.Field, col.Field)
-		}
-
-		if fkTable, exists := foreignKeys[col.Field]; exists {
-			tags = fmt.Sprintf("`db:\"%s\" gorm:\"index;column:%s\"`", col.Field, col.Field)
-			colType = "int"
-
-			modelContent += fmt.Sprintf("\n\t%s %s %s", fieldName, colType, tags)
-
-			relatedStruct := titleCase.String(strings.ReplaceAll(fkTable, "_", " "))
-			relatedStruct = strings.ReplaceAll(relatedStruct, " ", "")
-			if strings.HasSuffix(relatedStruct, "s") {
-				relatedStruct = relatedStruct[:len(relatedStruct)-1]
-			}
-
-			modelContent += fmt.Sprintf("\n\t%s %s `gorm:\"foreignKey:%s;references:ID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;\"`",
-				relatedStruct, relatedStruct, fieldName)
-			continue
+		return fmt.Errorf("rendering model %s: %w", structName, err)
+	}
+	if err := os.WriteFile(modelFilename, source, 0644); err != nil {
+		return fmt.Errorf("writing model file: %w", err)
+	}
+
+	fmt.Println("✅ Model file generated:", modelFilename)
+	return nil
+}
+
+// modelSource the Go source of the model struct of a table and its relations
+func modelSource(table TableSchema, relations []TableRelation) ([]byte, error) {
+	structName := inflection.ModelName(table.Name)
+	belongsTo := map[string]TableRelation{}
+	for _, relation := range relations {
+		if relation.Type == BelongsTo {
+			belongsTo[relation.ForeignKey] = relation
+		}
+	}
+
+	var fields []structField
+	var enums []enumType
+	imports := map[string]bool{}
+
+	for _, col := range table.Columns {
+		fieldName := inflection.Camelize(col.Name)
+
+		colType, pkg := goColumnType(col)
+		if pkg != "" {
+			imports[pkg] = true
+		}
+		if len(col.EnumValues) > 0 {
+			enum := newEnumType(structName+fieldName, col.EnumValues)
+			enums = append(enums, enum)
+			colType = enum.Name
 		}
+		if col.Nullable && !col.PrimaryKey && colType != "[]byte" && colType != "json.RawMessage" {
+			colType = "*" + colType
+		}
+
+		gormTag := "column:" + col.Name
+		if col.PrimaryKey {
+			gormTag = "primaryKey;" + gormTag
+		}
+		relation, isForeignKey := belongsTo[col.Name]
+		if isForeignKey {
+			gormTag = "index;" + gormTag
+		}
+		if col.Comment != "" && !strings.ContainsAny(col.Comment, "\";`") {
+			gormTag += ";comment:" + col.Comment
+		}
+
+		fields = append(fields, structField{
+			Name:    fieldName,
+			Type:    colType,
+			Tag:     columnTags(col, gormTag),
+			Comment: strings.Join(strings.Fields(col.Comment), " "),
+		})
 
-		modelContent += fmt.Sprintf("\n\t%s %s %s", fieldName, colType, tags)
+		if isForeignKey {
+			fields = append(fields, relationField(relation))
+		}
 	}
 
-	modelContent += "\n}"
+	for _, relation := range relations {
+		if relation.Type != BelongsTo {
+			fields = append(fields, relationField(relation))
+		}
+	}
 
-	_, err = file.WriteString(modelContent)
-	if err != nil {
-		golog.Fatal("❌ Error writing to model file:", err)
+	var importList []string
+	for pkg := range imports {
+		importList = append(importList, pkg)
 	}
+	sort.Strings(importList)
 
-	fmt.Println("✅ Model file generated:", modelFilename)
+	data := modelData{Name: structName, Imports: importList, Enums: enums, Fields: fields}
+	if inflection.TableName(structName) != table.Name {
+		data.Table = table.Name
+	}
+	return renderGo("model", data)
 }
 
-func ExtractTableName(migrationName string) string {
-	re := regexp.MustCompile(`Up\d+([A-Za-z]+)`)
-	matches := re.FindStringSubmatch(migrationName)
-	if len(matches) == 2 {
-		return strings.ToLower(matches[1])
+// relationField the struct field of a relation, pointer for a self reference
+func relationField(relation TableRelation) structField {
+	related := inflection.ModelName(relation.RelatedTable)
+	jsonTag := fmt.Sprintf(`json:"%s,omitempty"`, toSnakeCase(relation.Field))
+
+	switch relation.Type {
+	case HasMany:
+		return structField{
+			Name: relation.Field,
+			Type: "[]" + related,
+			Tag:  fmt.Sprintf(`%s gorm:"foreignKey:%s;references:%s"`, jsonTag, inflection.Camelize(relation.ForeignKey), inflection.Camelize(relation.References)),
+		}
+	case ManyToMany:
+		return structField{
+			Name: relation.Field,
+			Type: "[]" + related,
+			Tag: fmt.Sprintf(`%s gorm:"many2many:%s;foreignKey:%s;joinForeignKey:%s;references:%s;joinReferences:%s"`,
+				jsonTag, relation.JoinTable, inflection.Camelize(relation.ForeignKey), inflection.Camelize(relation.JoinForeignKey),
+				inflection.Camelize(relation.References), inflection.Camelize(relation.JoinReferences)),
+		}
+	}
+
+	if relation.RelatedTable == relation.Table {
+		related = "*" + related
+	}
+	gormTag := fmt.Sprintf("foreignKey:%s;references:%s", inflection.Camelize(relation.ForeignKey), inflection.Camelize(relation.References))
+	if constraint := constraintOption(relation.OnUpdate, relation.OnDelete); constraint != "" {
+		gormTag += ";" + constraint + ";"
+	}
+	return structField{
+		Name: relation.Field,
+		Type: related,
+		Tag:  fmt.Sprintf(`%s gorm:"%s"`, jsonTag, gormTag),
 	}
-	return ""
 }
 
-func CreateMigration(tableName string) {
-	timestamp := time.Now().Format("20060102150405")
+// constraintOption the gorm constraint option of the referential actions of a
+// foreign key, empty when both are the default NO ACTION
+func constraintOption(onUpdate, onDelete string) string {
+	var actions []string
+	for _, action := range []struct{ event, rule string }{{"OnUpdate", onUpdate}, {"OnDelete", onDelete}} {
+		rule := strings.ToUpper(strings.TrimSpace(action.rule))
+		if rule != "" && rule != "NO ACTION" {
+			actions = append(actions, action.event+":"+rule)
+		}
+	}
+	if len(actions) == 0 {
+		return ""
+	}
+	return "constraint:" + strings.Join(actions, ",")
+}
+
+// structField a field of a generated struct, Tag without the surrounding backticks
+type structField struct{ Name, Type, Tag, Comment string }
+
+type enumValue struct{ Name, Value string }
+
+// enumType a named string type generated for an enum column
+type enumType struct {
+	Name   string
+	Values []enumValue
+}
+
+func newEnumType(name string, values []string) enumType {
+	enum := enumType{Name: name}
 	titleCase := cases.Title(language.English)
-	structName := titleCase.String(strings.ReplaceAll(tableName, "_", " "))
-	structName = strings.ReplaceAll(structName, " ", "")
+	nonIdent := regexp.MustCompile(`[^A-Za-z0-9]+`)
+	seen := map[string]bool{}
+	for i, value := range values {
+		constName := name + strings.ReplaceAll(titleCase.String(nonIdent.ReplaceAllString(value, " ")), " ", "")
+		if constName == name || seen[constName] {
+			constName = fmt.Sprintf("%s%d", name, i+1)
+		}
+		seen[constName] = true
+		enum.Values = append(enum.Values, enumValue{Name: constName, Value: value})
+	}
+	return enum
+}
 
-	funcName := fmt.Sprintf("Up%s%s", timestamp, structName)
-	downFuncName := fmt.Sprintf("Down%s%s", timestamp, structName)
-	filename := fmt.Sprintf("migrations/%s_%s.go", timestamp, tableName)
+// goColumnType the Go type of a column and the package it needs, if any
+func goColumnType(col Column) (string, string) {
+	integer := func(signed, unsigned string) (string, string) {
+		if col.Unsigned {
+			return unsigned, ""
+		}
+		return signed, ""
+	}
 
-	content := fmt.Sprintf(`package migrations
+	switch col.DataType {
+	case "tinyint":
+		if strings.HasPrefix(col.ColumnType, "tinyint(1)") {
+			return "bool", ""
+		}
+		return integer("int8", "uint8")
+	case "bool", "boolean":
+		return "bool", ""
+	case "bit":
+		if col.ColumnType == "bit(1)" {
+			return "bool", ""
+		}
+		return "uint64", ""
+	case "smallint":
+		return integer("int16", "uint16")
+	case "mediumint", "int", "integer":
+		return integer("int", "uint")
+	case "bigint":
+		return integer("int64", "uint64")
+	case "year":
+		return "int", ""
+	case "float":
+		return "float32", ""
+	case "double", "decimal", "numeric", "real":
+		return "float64", ""
+	case "date", "datetime", "timestamp":
+		return "time.Time", "time"
+	case "json":
+		return "json.RawMessage", "encoding/json"
+	case "binary", "varbinary", "tinyblob", "blob", "mediumblob", "longblob":
+		return "[]byte", ""
+	}
+	return "string", ""
+}
 
-import (
-	"time"
-	"gorm.io/gorm"
-)
+// columnTags the db, json, gorm and validate tags of a generated model field
+func columnTags(col Column, gormTag string) string {
+	jsonTag := col.Name
+	if col.Nullable {
+		jsonTag += ",omitempty"
+	}
+
+	var rules []string
+	colType, _ := goColumnType(col)
+	required := !col.Nullable && col.Default == nil && !col.AutoIncrement && !col.PrimaryKey && colType != "bool"
+	if required {
+		rules = append(rules, "required")
+	} else if col.Nullable {
+		rules = append(rules, "omitempty")
+	}
+	if col.Length > 0 && colType == "string" && len(col.EnumValues) == 0 {
+		rules = append(rules, fmt.Sprintf("max=%d", col.Length))
+	}
+	if len(col.EnumValues) > 0 && !enumHasSpaces(col.EnumValues) {
+		rules = append(rules, "oneof="+strings.Join(col.EnumValues, " "))
+	}
 
-func %s(db *gorm.DB) error {
-	type %s struct {
-		ID        uint           `+"`gorm:\"primaryKey\"`"+`
-		Name      string         `+"`gorm:\"type:varchar(100)\"`"+`
-		CreatedAt time.Time      `+"`gorm:\"autoCreateTime\"`"+`
-		UpdatedAt time.Time      `+"`gorm:\"autoUpdateTime\"`"+`
+	tags := fmt.Sprintf(`db:"%s" json:"%s" gorm:"%s"`, col.Name, jsonTag, gormTag)
+	if len(rules) > 0 && !(len(rules) == 1 && rules[0] == "omitempty") {
+		tags += fmt.Sprintf(` validate:"%s"`, strings.Join(rules, ","))
 	}
-	return db.AutoMigrate(&%s{})
+	return tags
 }
 
-func %s(db *gorm.DB) error {
-	return db.Migrator().DropTable("%s")
+func enumHasSpaces(values []string) bool {
+	for _, value := range values {
+		if value == "" || strings.ContainsAny(value, " ,\"'`") {
+			return true
+		}
+	}
+	return false
 }
-`, funcName, structName, structName, downFuncName, tableName)
 
-	err := os.WriteFile(filename, []byte(content), 0644)
-	if err != nil {
+func CreateMigration(tableName string) {
+	timestamp := time.Now().Format("20060102150405")
+	structName := inflection.Camelize(tableName)
+
+	funcName := fmt.Sprintf("Up%s%s", timestamp, structName)
+	downFuncName := fmt.Sprintf("Down%s%s", timestamp, structName)
+	filename := fmt.Sprintf("migrations/%s_%s.go", timestamp, tableName)
+
+	data := migrationData{
+		UpName:     funcName,
+		DownName:   downFuncName,
+		StructName: structName,
+		Table:      tableName,
+	}
+	if err := writeGoFile(filename, "migration", data); err != nil {
 		fmt.Println("❌ Error creating migration file:", err)
+		return
 	}
 
 	fmt.Println("✅ Migration file created:", filename)
 	UpdateRegistryMigrations()
 }
 
-func CreateModels(db *gorm.DB) {
-	tables := []string{}
-	db.Raw("SHOW TABLES").Scan(&tables)
-
-	excludedTables := map[string]bool{
-		"migrations": true,
-		"registry":   true,
+func CreateModels(db *gorm.DB) error {
+	tables, err := LoadSchema(db)
+	if err != nil {
+		return fmt.Errorf("reading schema: %w", err)
 	}
 
+	relations := InferRelations(tables)
 	for _, table := range tables {
-		if excludedTables[table] {
-			fmt.Print(excludedTables[table])
+		if IsJoinTable(table) {
+			fmt.Println("🔗 Join table", table.Name, "mapped as many-to-many, no model generated")
 			continue
 		}
-		CreateModelFile(db, table)
+		if err := writeModelFile(table, relations[table.Name]); err != nil {
+			return err
+		}
 	}
 
-	updateModelRegistry()
+	if err := updateModelRegistry(); err != nil {
+		return err
+	}
 	fmt.Println("✅ Models generated successfully!")
+	return nil
 }
@@ -0,0 +1,58 @@
+package constructmigrations
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestModelSourceForeignKeyActions(t *testing.T) {
+	id := Column{Name: "id", DataType: "int", ColumnType: "int", PrimaryKey: true, AutoIncrement: true}
+	tables := []TableSchema{
+		{Name: "categories", Columns: []Column{id}},
+		{Name: "users", Columns: []Column{id}},
+		{
+			Name: "orders",
+			Columns: []Column{
+				id,
+				{Name: "category_id", DataType: "int", ColumnType: "int"},
+				{Name: "user_id", DataType: "int", ColumnType: "int", Nullable: true},
+			},
+			ForeignKeys: []ForeignKey{
+				{Name: "fk_orders_category", Table: "orders", Columns: []string{"category_id"}, ReferencedTable: "categories", ReferencedColumns: []string{"id"}, OnUpdate: "NO ACTION", OnDelete: "CASCADE"},
+				{Name: "fk_orders_user", Table: "orders", Columns: []string{"user_id"}, ReferencedTable: "users", ReferencedColumns: []string{"id"}, OnUpdate: "CASCADE", OnDelete: "SET NULL"},
+			},
+		},
+	}
+
+	source, err := modelSource(tables[2], InferRelations(tables)["orders"])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		`gorm:"foreignKey:CategoryId;references:Id;constraint:OnDelete:CASCADE;"`,
+		`gorm:"foreignKey:UserId;references:Id;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`,
+	} {
+		if !strings.Contains(string(source), want) {
+			t.Errorf("model has no %s:\n%s", want, source)
+		}
+	}
+}
+
+func TestModelSourceTableName(t *testing.T) {
+	id := Column{Name: "id", DataType: "int", ColumnType: "int", PrimaryKey: true, AutoIncrement: true}
+	for table, wantTableName := range map[string]bool{
+		"order_items": false,
+		"people":      false,
+		"staff":       false,
+		"staffs":      true, // Staff maps to staff
+	} {
+		source, err := modelSource(TableSchema{Name: table, Columns: []Column{id}}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Contains(string(source), `return "`+table+`"`); got != wantTableName {
+			t.Errorf("%s: TableName generated = %v, want %v:\n%s", table, got, wantTableName, source)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package constructmigrations
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DestructiveActions actions that drop tables or data, with what they will do
+var DestructiveActions = map[string]string{
+	"fresh":    "drop EVERY table in the database, load the schema dump if there is one and run the migrations again",
+	"down":     "drop the table and delete its model file",
+	"down-all": "drop EVERY table in the database and delete all model files",
+	"rollback": "run the Down functions of the last migrations",
+	"reset":    "run the Down functions of every applied migration",
+	"refresh":  "roll back every migration and run them all again",
+	"squash":   "delete the migration files up to --before, replace them with a baseline and rewrite their history",
+}
+
+// ConfirmDestructive refuses destructive actions in production unless force
+// is set, and otherwise asks for confirmation on in
+func ConfirmDestructive(action, env, database string, force bool, in io.Reader, out io.Writer) error {
+	description, destructive := DestructiveActions[action]
+	if !destructive || force {
+		return nil
+	}
+
+	if env == "production" {
+		return fmt.Errorf("refusing to run %q with GO_ENV=production, pass --force to run it anyway", action)
+	}
+
+	fmt.Fprintf(out, "⚠️ --action=%s will %s on database %q.\n", action, description, database)
+	fmt.Fprint(out, "Type 'yes' to continue: ")
+
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && answer == "" {
+		return fmt.Errorf("no confirmation given, aborting")
+	}
+	if strings.ToLower(strings.TrimSpace(answer)) != "yes" {
+		return fmt.Errorf("aborted")
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package constructmigrations
+
+import (
+	"backends/migrations"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const HistoryTable = "schema_migrations"
+
+// MigrationRecord one applied migration in the schema_migrations table
+type MigrationRecord struct {
+	ID        uint      `gorm:"primaryKey"`
+	Name      string    `gorm:"type:varchar(255);uniqueIndex"`
+	Batch     int       `gorm:"index"`
+	Checksum  string    `gorm:"type:varchar(64)"`
+	AppliedAt time.Time `gorm:"autoCreateTime"`
+}
+
+func (MigrationRecord) TableName() string {
+	return HistoryTable
+}
+
+func EnsureHistoryTable(db *gorm.DB) error {
+	return db.AutoMigrate(&MigrationRecord{})
+}
+
+// AppliedMigrations history rows in the order they were applied
+func AppliedMigrations(db *gorm.DB) ([]MigrationRecord, error) {
+	var records []MigrationRecord
+	err := db.Order("batch, id").Find(&records).Error
+	return records, err
+}
+
+func NextBatch(db *gorm.DB) (int, error) {
+	var batch int
+	err := db.Model(&MigrationRecord{}).Select("COALESCE(MAX(batch), 0)").Scan(&batch).Error
+	return batch + 1, err
+}
+
+func RecordMigration(db *gorm.DB, name string, batch int, checksum string) error {
+	return db.Create(&MigrationRecord{
+		Name:      name,
+		Batch:     batch,
+		Checksum:  checksum,
+		AppliedAt: time.Now(),
+	}).Error
+}
+
+// MigrationChecksum sha256 of the source file of a migration (the .up.sql
+// file for SQL migrations), empty when the file is not found
+func MigrationChecksum(name string) string {
+	version := migrations.Migration{Name: name}.Version()
+	if version == "" {
+		return ""
+	}
+
+	path := filepath.Join(migrationsDir, name+".up.sql")
+	if !isSQLMigration(name) {
+		files, _ := filepath.Glob(filepath.Join(migrationsDir, version+"_*.go"))
+		if len(files) == 0 {
+			return ""
+		}
+		path = files[0]
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,407 @@
+package constructmigrations
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ForeignKey a foreign key constraint as it exists in the database
+type ForeignKey struct {
+	Name              string
+	Table             string
+	Columns           []string
+	ReferencedTable   string
+	ReferencedColumns []string
+	OnUpdate          string
+	OnDelete          string
+}
+
+// Tables the base tables of the current database (schema on Postgres), sorted
+func Tables(db *gorm.DB) ([]string, error) {
+	var tables []string
+	var err error
+	switch db.Dialector.Name() {
+	case MySQL:
+		err = db.Raw(`SELECT TABLE_NAME FROM information_schema.TABLES
+			WHERE TABLE_SCHEMA = DATABASE() AND TABLE_TYPE = 'BASE TABLE' ORDER BY TABLE_NAME`).Scan(&tables).Error
+	case Postgres:
+		err = db.Raw(`SELECT table_name FROM information_schema.tables
+			WHERE table_schema = current_schema() AND table_type = 'BASE TABLE' ORDER BY table_name`).Scan(&tables).Error
+	case SQLite:
+		err = db.Raw(`SELECT name FROM sqlite_master
+			WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name`).Scan(&tables).Error
+	default:
+		return nil, fmt.Errorf("table introspection is not supported for %s", db.Dialector.Name())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("listing tables: %w", err)
+	}
+	return tables, nil
+}
+
+// ForeignKeys the foreign key constraints defined on table, in constraint name order
+func ForeignKeys(db *gorm.DB, table string) ([]ForeignKey, error) {
+	switch db.Dialector.Name() {
+	case MySQL:
+		return mysqlForeignKeys(db, table)
+	case Postgres:
+		return postgresForeignKeys(db, table)
+	case SQLite:
+		return sqliteForeignKeys(db, table)
+	}
+	return nil, nil
+}
+
+// foreignKeyRow one column of a foreign key, rows of the same constraint are consecutive
+type foreignKeyRow struct {
+	Name             string `gorm:"column:CONSTRAINT_NAME"`
+	Column           string `gorm:"column:COLUMN_NAME"`
+	ReferencedTable  string `gorm:"column:REFERENCED_TABLE_NAME"`
+	ReferencedColumn string `gorm:"column:REFERENCED_COLUMN_NAME"`
+	UpdateRule       string `gorm:"column:UPDATE_RULE"`
+	DeleteRule       string `gorm:"column:DELETE_RULE"`
+}
+
+func mysqlForeignKeys(db *gorm.DB, table string) ([]ForeignKey, error) {
+	var rows []foreignKeyRow
+	err := db.Raw(`SELECT k.CONSTRAINT_NAME, k.COLUMN_NAME, k.REFERENCED_TABLE_NAME, k.REFERENCED_COLUMN_NAME, r.UPDATE_RULE, r.DELETE_RULE
+		FROM information_schema.KEY_COLUMN_USAGE k
+		JOIN information_schema.REFERENTIAL_CONSTRAINTS r
+			ON r.CONSTRAINT_SCHEMA = k.CONSTRAINT_SCHEMA AND r.CONSTRAINT_NAME = k.CONSTRAINT_NAME
+		WHERE k.TABLE_SCHEMA = DATABASE() AND k.TABLE_NAME = ? AND k.REFERENCED_TABLE_NAME IS NOT NULL
+		ORDER BY k.CONSTRAINT_NAME, k.ORDINAL_POSITION`, table).Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("reading foreign keys of %s: %w", table, err)
+	}
+	return groupForeignKeys(table, rows), nil
+}
+
+// postgresForeignKeys reads pg_constraint, pairing conkey and confkey by position
+func postgresForeignKeys(db *gorm.DB, table string) ([]ForeignKey, error) {
+	var rows []foreignKeyRow
+	err := db.Raw(`SELECT con.conname AS "CONSTRAINT_NAME", att.attname AS "COLUMN_NAME",
+			ref.relname AS "REFERENCED_TABLE_NAME", ratt.attname AS "REFERENCED_COLUMN_NAME",
+			CASE con.confupdtype WHEN 'c' THEN 'CASCADE' WHEN 'n' THEN 'SET NULL' WHEN 'd' THEN 'SET DEFAULT' WHEN 'r' THEN 'RESTRICT' ELSE 'NO ACTION' END AS "UPDATE_RULE",
+			CASE con.confdeltype WHEN 'c' THEN 'CASCADE' WHEN 'n' THEN 'SET NULL' WHEN 'd' THEN 'SET DEFAULT' WHEN 'r' THEN 'RESTRICT' ELSE 'NO ACTION' END AS "DELETE_RULE"
+		FROM pg_catalog.pg_constraint con
+		JOIN pg_catalog.pg_class cls ON cls.oid = con.conrelid
+		JOIN pg_catalog.pg_namespace ns ON ns.oid = cls.relnamespace
+		JOIN pg_catalog.pg_class ref ON ref.oid = con.confrelid
+		CROSS JOIN LATERAL unnest(con.conkey, con.confkey) WITH ORDINALITY AS k(attnum, refattnum, ord)
+		JOIN pg_catalog.pg_attribute att ON att.attrelid = con.conrelid AND att.attnum = k.attnum
+		JOIN pg_catalog.pg_attribute ratt ON ratt.attrelid = con.confrelid AND ratt.attnum = k.refattnum
+		WHERE con.contype = 'f' AND ns.nspname = current_schema() AND cls.relname = ?
+		ORDER BY con.conname, k.ord`, table).Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("reading foreign keys of %s: %w", table, err)
+	}
+	return groupForeignKeys(table, rows), nil
+}
+
+// sqliteForeignKeys reads PRAGMA foreign_key_list, SQLite keeps no constraint
+// names so they are named after the table and the key id
+func sqliteForeignKeys(db *gorm.DB, table string) ([]ForeignKey, error) {
+	var pragma []struct {
+		ID       int    `gorm:"column:id"`
+		Seq      int    `gorm:"column:seq"`
+		Table    string `gorm:"column:table"`
+		From     string `gorm:"column:from"`
+		To       string `gorm:"column:to"`
+		OnUpdate string `gorm:"column:on_update"`
+		OnDelete string `gorm:"column:on_delete"`
+	}
+	if err := db.Raw("SELECT * FROM pragma_foreign_key_list(?) ORDER BY id, seq", table).Scan(&pragma).Error; err != nil {
+		return nil, fmt.Errorf("reading foreign keys of %s: %w", table, err)
+	}
+
+	rows := make([]foreignKeyRow, 0, len(pragma))
+	for _, row := range pragma {
+		rows = append(rows, foreignKeyRow{
+			Name:             fmt.Sprintf("fk_%s_%d", table, row.ID),
+			Column:           row.From,
+			ReferencedTable:  row.Table,
+			ReferencedColumn: row.To,
+			UpdateRule:       row.OnUpdate,
+			DeleteRule:       row.OnDelete,
+		})
+	}
+	return groupForeignKeys(table, rows), nil
+}
+
+func groupForeignKeys(table string, rows []foreignKeyRow) []ForeignKey {
+	var keys []ForeignKey
+	for _, row := range rows {
+		if len(keys) == 0 || keys[len(keys)-1].Name != row.Name {
+			keys = append(keys, ForeignKey{
+				Name:            row.Name,
+				Table:           table,
+				ReferencedTable: row.ReferencedTable,
+				OnUpdate:        row.UpdateRule,
+				OnDelete:        row.DeleteRule,
+			})
+		}
+		key := &keys[len(keys)-1]
+		key.Columns = append(key.Columns, row.Column)
+		key.ReferencedColumns = append(key.ReferencedColumns, row.ReferencedColumn)
+	}
+	return keys
+}
+
+// Column a table column as described by information_schema.COLUMNS
+type Column struct {
+	Name          string
+	DataType      string // int, varchar, enum, ...
+	ColumnType    string // int(10) unsigned, varchar(100), enum('a','b'), ...
+	Nullable      bool
+	PrimaryKey    bool
+	Unique        bool
+	AutoIncrement bool
+	Unsigned      bool
+	Default       *string
+	Length        int64 // character length of char/varchar columns
+	Comment       string
+	EnumValues    []string
+}
+
+// Columns the columns of table in ordinal order, DataType uses the MySQL names
+// (int, varchar, datetime, ...) on every engine
+func Columns(db *gorm.DB, table string) ([]Column, error) {
+	switch db.Dialector.Name() {
+	case MySQL:
+		return mysqlColumns(db, table)
+	case Postgres:
+		return postgresColumns(db, table)
+	case SQLite:
+		return sqliteColumns(db, table)
+	}
+	return nil, fmt.Errorf("column introspection is not supported for %s", db.Dialector.Name())
+}
+
+func mysqlColumns(db *gorm.DB, table string) ([]Column, error) {
+	var rows []struct {
+		Name       string  `gorm:"column:COLUMN_NAME"`
+		DataType   string  `gorm:"column:DATA_TYPE"`
+		ColumnType string  `gorm:"column:COLUMN_TYPE"`
+		Nullable   string  `gorm:"column:IS_NULLABLE"`
+		Key        string  `gorm:"column:COLUMN_KEY"`
+		Default    *string `gorm:"column:COLUMN_DEFAULT"`
+		Extra      string  `gorm:"column:EXTRA"`
+		Comment    string  `gorm:"column:COLUMN_COMMENT"`
+		Length     *int64  `gorm:"column:CHARACTER_MAXIMUM_LENGTH"`
+	}
+	err := db.Raw(`SELECT COLUMN_NAME, DATA_TYPE, COLUMN_TYPE, IS_NULLABLE, COLUMN_KEY, COLUMN_DEFAULT, EXTRA, COLUMN_COMMENT, CHARACTER_MAXIMUM_LENGTH
+		FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?
+		ORDER BY ORDINAL_POSITION`, table).Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("reading columns of %s: %w", table, err)
+	}
+
+	columns := make([]Column, 0, len(rows))
+	for _, row := range rows {
+		column := Column{
+			Name:          row.Name,
+			DataType:      strings.ToLower(row.DataType),
+			ColumnType:    strings.ToLower(row.ColumnType),
+			Nullable:      row.Nullable == "YES",
+			PrimaryKey:    row.Key == "PRI",
+			Unique:        row.Key == "UNI",
+			AutoIncrement: strings.Contains(strings.ToLower(row.Extra), "auto_increment"),
+			Default:       row.Default,
+			Comment:       row.Comment,
+		}
+		column.Unsigned = strings.Contains(column.ColumnType, "unsigned")
+		if row.Length != nil {
+			column.Length = *row.Length
+		}
+		if column.DataType == "enum" {
+			column.EnumValues = parseEnumValues(row.ColumnType)
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+// postgresTypes Postgres data types by the MySQL name used in Column.DataType
+var postgresTypes = map[string]string{
+	"smallint":                    "smallint",
+	"integer":                     "int",
+	"bigint":                      "bigint",
+	"boolean":                     "bool",
+	"real":                        "float",
+	"double precision":            "double",
+	"numeric":                     "decimal",
+	"character varying":           "varchar",
+	"character":                   "char",
+	"text":                        "text",
+	"date":                        "date",
+	"timestamp without time zone": "timestamp",
+	"timestamp with time zone":    "timestamp",
+	"time without time zone":      "time",
+	"time with time zone":         "time",
+	"json":                        "json",
+	"jsonb":                       "json",
+	"bytea":                       "blob",
+	"uuid":                        "char",
+}
+
+func postgresColumns(db *gorm.DB, table string) ([]Column, error) {
+	var rows []struct {
+		Name       string  `gorm:"column:COLUMN_NAME"`
+		DataType   string  `gorm:"column:DATA_TYPE"`
+		UDTName    string  `gorm:"column:UDT_NAME"`
+		ColumnType string  `gorm:"column:COLUMN_TYPE"`
+		Nullable   string  `gorm:"column:IS_NULLABLE"`
+		Key        string  `gorm:"column:COLUMN_KEY"`
+		Default    *string `gorm:"column:COLUMN_DEFAULT"`
+		Identity   string  `gorm:"column:IS_IDENTITY"`
+		Comment    string  `gorm:"column:COLUMN_COMMENT"`
+		Length     *int64  `gorm:"column:CHARACTER_MAXIMUM_LENGTH"`
+	}
+	err := db.Raw(`SELECT c.column_name AS "COLUMN_NAME", c.data_type AS "DATA_TYPE", c.udt_name AS "UDT_NAME",
+			format_type(a.atttypid, a.atttypmod) AS "COLUMN_TYPE", c.is_nullable AS "IS_NULLABLE",
+			c.column_default AS "COLUMN_DEFAULT", c.is_identity AS "IS_IDENTITY",
+			c.character_maximum_length AS "CHARACTER_MAXIMUM_LENGTH",
+			COALESCE(col_description(a.attrelid, a.attnum), '') AS "COLUMN_COMMENT",
+			(SELECT CASE WHEN bool_or(i.indisprimary) THEN 'PRI' WHEN bool_or(i.indisunique AND i.indnatts = 1) THEN 'UNI' ELSE '' END
+				FROM pg_catalog.pg_index i WHERE i.indrelid = a.attrelid AND a.attnum = ANY(i.indkey)) AS "COLUMN_KEY"
+		FROM information_schema.columns c
+		JOIN pg_catalog.pg_namespace ns ON ns.nspname = c.table_schema
+		JOIN pg_catalog.pg_class cls ON cls.relnamespace = ns.oid AND cls.relname = c.table_name
+		JOIN pg_catalog.pg_attribute a ON a.attrelid = cls.oid AND a.attname = c.column_name
+		WHERE c.table_schema = current_schema() AND c.table_name = ?
+		ORDER BY c.ordinal_position`, table).Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("reading columns of %s: %w", table, err)
+	}
+
+	columns := make([]Column, 0, len(rows))
+	for _, row := range rows {
+		column := Column{
+			Name:       row.Name,
+			DataType:   postgresTypes[row.DataType],
+			ColumnType: strings.ToLower(row.ColumnType),
+			Nullable:   row.Nullable == "YES",
+			PrimaryKey: row.Key == "PRI",
+			Unique:     row.Key == "UNI",
+			Default:    row.Default,
+			Comment:    row.Comment,
+		}
+		if column.DataType == "" {
+			column.DataType = strings.ToLower(row.DataType)
+		}
+		if row.Identity == "YES" || (row.Default != nil && strings.HasPrefix(*row.Default, "nextval(")) {
+			column.AutoIncrement = true
+			column.Default = nil
+		}
+		if row.Length != nil {
+			column.Length = *row.Length
+		}
+		if row.DataType == "USER-DEFINED" {
+			var values []string
+			err := db.Raw(`SELECT e.enumlabel FROM pg_catalog.pg_enum e
+				JOIN pg_catalog.pg_type t ON t.oid = e.enumtypid
+				WHERE t.typname = ? ORDER BY e.enumsortorder`, row.UDTName).Scan(&values).Error
+			if err != nil {
+				return nil, fmt.Errorf("reading values of enum %s: %w", row.UDTName, err)
+			}
+			if len(values) > 0 {
+				column.DataType = "enum"
+				column.EnumValues = values
+			}
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+// sqliteColumns reads PRAGMA table_info, DataType is the first word of the
+// declared type and an INTEGER primary key is the auto-incrementing rowid
+func sqliteColumns(db *gorm.DB, table string) ([]Column, error) {
+	var rows []struct {
+		Name       string  `gorm:"column:name"`
+		Type       string  `gorm:"column:type"`
+		NotNull    int     `gorm:"column:notnull"`
+		Default    *string `gorm:"column:dflt_value"`
+		PrimaryKey int     `gorm:"column:pk"`
+	}
+	if err := db.Raw("SELECT * FROM pragma_table_info(?) ORDER BY cid", table).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("reading columns of %s: %w", table, err)
+	}
+
+	var unique []string
+	err := db.Raw(`SELECT ii.name FROM pragma_index_list(?) il, pragma_index_info(il.name) ii
+		WHERE il."unique" = 1 AND il.origin != 'pk'
+		GROUP BY il.name HAVING COUNT(*) = 1`, table).Scan(&unique).Error
+	if err != nil {
+		return nil, fmt.Errorf("reading indexes of %s: %w", table, err)
+	}
+	uniqueColumns := map[string]bool{}
+	for _, name := range unique {
+		uniqueColumns[name] = true
+	}
+
+	primaryKeys := 0
+	for _, row := range rows {
+		if row.PrimaryKey > 0 {
+			primaryKeys++
+		}
+	}
+
+	columns := make([]Column, 0, len(rows))
+	for _, row := range rows {
+		columnType := strings.ToLower(strings.TrimSpace(row.Type))
+		dataType := columnType
+		if i := strings.IndexAny(dataType, "( "); i >= 0 {
+			dataType = dataType[:i]
+		}
+		column := Column{
+			Name:       row.Name,
+			DataType:   dataType,
+			ColumnType: columnType,
+			Nullable:   row.NotNull == 0 && row.PrimaryKey == 0,
+			PrimaryKey: row.PrimaryKey > 0,
+			Unique:     uniqueColumns[row.Name],
+			Unsigned:   strings.Contains(columnType, "unsigned"),
+			Default:    row.Default,
+		}
+		column.AutoIncrement = column.PrimaryKey && primaryKeys == 1 && dataType == "integer"
+		if dataType == "varchar" || dataType == "char" {
+			fmt.Sscanf(columnType[len(dataType):], "(%d)", &column.Length)
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+// parseEnumValues the values of an enum('a','b') column type
+func parseEnumValues(columnType string) []string {
+	start, end := strings.Index(columnType, "("), strings.LastIndex(columnType, ")")
+	if start < 0 || end <= start {
+		return nil
+	}
+
+	var values []string
+	var current strings.Builder
+	inQuote := false
+	list := columnType[start+1 : end]
+	for i := 0; i < len(list); i++ {
+		c := list[i]
+		switch {
+		case c == '\'' && inQuote && i+1 < len(list) && list[i+1] == '\'':
+			current.WriteByte('\'')
+			i++
+		case c == '\'':
+			if inQuote {
+				values = append(values, current.String())
+				current.Reset()
+			}
+			inQuote = !inQuote
+		case inQuote:
+			current.WriteByte(c)
+		}
+	}
+	return values
+}
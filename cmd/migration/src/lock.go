@@ -0,0 +1,186 @@
+package constructmigrations
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const LockTable = "schema_migrations_lock"
+
+var ErrLockTimeout = errors.New("timed out waiting for the migration lock")
+
+// lockPollInterval how often the Postgres and table locks are retried
+const lockPollInterval = 500 * time.Millisecond
+
+// AcquireMigrationLock blocks until this process holds the migration lock, so
+// two processes never apply migrations at the same time. MySQL uses GET_LOCK,
+// Postgres an advisory lock, other engines a row in schema_migrations_lock.
+// The returned func releases the lock.
+func AcquireMigrationLock(db *gorm.DB, timeout time.Duration) (func(), error) {
+	switch db.Dialector.Name() {
+	case "mysql":
+		return acquireMySQLLock(db, timeout)
+	case "postgres":
+		return acquirePostgresLock(db, timeout)
+	default:
+		return acquireTableLock(db, timeout)
+	}
+}
+
+// pinnedConn session locks belong to one connection, so they must not go through the pool
+func pinnedConn(db *gorm.DB) (*sql.Conn, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	return sqlDB.Conn(context.Background())
+}
+
+func acquireMySQLLock(db *gorm.DB, timeout time.Duration) (func(), error) {
+	conn, err := pinnedConn(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var lockName string
+	if err := conn.QueryRowContext(context.Background(), "SELECT CONCAT(DATABASE(), '.', ?)", HistoryTable).Scan(&lockName); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	getLock := func(seconds int) (bool, error) {
+		var acquired sql.NullInt64
+		err := conn.QueryRowContext(context.Background(), "SELECT GET_LOCK(?, ?)", lockName, seconds).Scan(&acquired)
+		return acquired.Valid && acquired.Int64 == 1, err
+	}
+
+	acquired, err := getLock(0)
+	if err == nil && !acquired {
+		var holder sql.NullInt64
+		conn.QueryRowContext(context.Background(), "SELECT IS_USED_LOCK(?)", lockName).Scan(&holder)
+		fmt.Printf("⏳ Migration lock is held by another process (connection %d), waiting up to %s...\n", holder.Int64, timeout)
+		// GET_LOCK waits whole seconds, round up so a sub-second timeout still waits
+		acquired, err = getLock(int(math.Ceil(timeout.Seconds())))
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if !acquired {
+		conn.Close()
+		return nil, ErrLockTimeout
+	}
+
+	return func() {
+		conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", lockName)
+		conn.Close()
+	}, nil
+}
+
+func acquirePostgresLock(db *gorm.DB, timeout time.Duration) (func(), error) {
+	conn, err := pinnedConn(db)
+	if err != nil {
+		return nil, err
+	}
+
+	const key = "hashtext(current_database() || '." + HistoryTable + "')"
+	tryLock := func() (bool, error) {
+		var acquired bool
+		err := conn.QueryRowContext(context.Background(), "SELECT pg_try_advisory_lock("+key+")").Scan(&acquired)
+		return acquired, err
+	}
+
+	acquired, err := waitFor(tryLock, timeout, func() {
+		fmt.Printf("⏳ Migration lock is held by another process, waiting up to %s...\n", timeout)
+	})
+	if err != nil || !acquired {
+		conn.Close()
+		if err == nil {
+			err = ErrLockTimeout
+		}
+		return nil, err
+	}
+
+	return func() {
+		conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock("+key+")")
+		conn.Close()
+	}, nil
+}
+
+type migrationLock struct {
+	ID       int    `gorm:"primaryKey;autoIncrement:false"`
+	Owner    string `gorm:"type:varchar(255)"`
+	LockedAt time.Time
+}
+
+func (migrationLock) TableName() string {
+	return LockTable
+}
+
+func acquireTableLock(db *gorm.DB, timeout time.Duration) (func(), error) {
+	if err := db.AutoMigrate(&migrationLock{}); err != nil {
+		return nil, err
+	}
+
+	hostname, _ := os.Hostname()
+	owner := fmt.Sprintf("%s:%d", hostname, os.Getpid())
+
+	// a failed insert is the expected outcome while the lock is held, keep it out of the log
+	quiet := db.Session(&gorm.Session{Logger: db.Logger.LogMode(logger.Silent)})
+
+	tryLock := func() (bool, error) {
+		lock := migrationLock{ID: 1, Owner: owner, LockedAt: time.Now()}
+		if err := quiet.Create(&lock).Error; err != nil {
+			var count int64
+			if countErr := db.Model(&migrationLock{}).Where("id = ?", 1).Count(&count).Error; countErr != nil || count == 0 {
+				return false, err
+			}
+			return false, nil
+		}
+		return true, nil
+	}
+
+	acquired, err := waitFor(tryLock, timeout, func() {
+		var current migrationLock
+		db.First(&current, 1)
+		fmt.Printf("⏳ Migration lock is held by %s since %s, waiting up to %s...\n",
+			current.Owner, current.LockedAt.Format("2006-01-02 15:04:05"), timeout)
+		fmt.Printf("   If no migration is running, delete the row from %s to release it.\n", LockTable)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !acquired {
+		return nil, ErrLockTimeout
+	}
+
+	return func() {
+		db.Where("id = ? AND owner = ?", 1, owner).Delete(&migrationLock{})
+	}, nil
+}
+
+// waitFor polls try until it succeeds or timeout passes, calling onWait once before the first wait
+func waitFor(try func() (bool, error), timeout time.Duration, onWait func()) (bool, error) {
+	deadline := time.Now().Add(timeout)
+	for first := true; ; first = false {
+		acquired, err := try()
+		if err != nil || acquired {
+			return acquired, err
+		}
+		if time.Now().After(deadline) {
+			return false, nil
+		}
+		if first {
+			onWait()
+		}
+		time.Sleep(lockPollInterval)
+	}
+}
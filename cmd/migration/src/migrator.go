@@ -0,0 +1,220 @@
+package constructmigrations
+
+import (
+	"backends/migrations"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type RunOptions struct {
+	// AllowOutOfOrder runs pending migrations older than the newest applied one
+	AllowOutOfOrder bool
+}
+
+// SortedMigrations Go registry merged with the SQL file migrations, ordered
+// by timestamp prefix, then name
+func SortedMigrations() ([]migrations.Migration, error) {
+	sqlMigrations, err := LoadSQLMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	sorted := append([]migrations.Migration(nil), migrations.MigrationRegistry...)
+	sorted = append(sorted, sqlMigrations...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Version() != sorted[j].Version() {
+			return sorted[i].Version() < sorted[j].Version()
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted, nil
+}
+
+// RunPending applies every registered migration missing from the history
+// table as one new batch, in timestamp order, and returns the names that ran
+func RunPending(db *gorm.DB, opts RunOptions) ([]string, error) {
+	if err := EnsureHistoryTable(db); err != nil {
+		return nil, fmt.Errorf("creating %s table: %w", HistoryTable, err)
+	}
+	if _, err := RecordSquashedBaselines(db); err != nil {
+		return nil, err
+	}
+
+	pending, err := PendingMigrations(db, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	batch, err := NextBatch(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var ran []string
+	for _, migration := range pending {
+		fmt.Println("🔄 Running migration:", migration.Name)
+		if err := migration.Up(db); err != nil {
+			return ran, fmt.Errorf("%s: %w", migration.Name, err)
+		}
+		if err := RecordMigration(db, migration.Name, batch, MigrationChecksum(migration.Name)); err != nil {
+			return ran, fmt.Errorf("recording %s: %w", migration.Name, err)
+		}
+		ran = append(ran, migration.Name)
+	}
+
+	return ran, nil
+}
+
+// PendingMigrations registered migrations missing from the history table, in
+// timestamp order. A missing history table means nothing was applied yet.
+func PendingMigrations(db *gorm.DB, opts RunOptions) ([]migrations.Migration, error) {
+	var records []MigrationRecord
+	if db.Migrator().HasTable(HistoryTable) {
+		var err error
+		if records, err = AppliedMigrations(db); err != nil {
+			return nil, err
+		}
+	}
+
+	applied := map[string]bool{}
+	latest := ""
+	for _, record := range records {
+		applied[record.Name] = true
+		if version := (migrations.Migration{Name: record.Name}).Version(); version > latest {
+			latest = version
+		}
+	}
+
+	sorted, err := SortedMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	// a baseline whose squashed migrations all ran is recorded by RunPending, not
+	// run, and one whose squashed migrations only partly ran cannot be applied
+	for _, migration := range sorted {
+		squashed := SquashedMigrations(migration.Name)
+		if len(squashed) == 0 || applied[migration.Name] {
+			continue
+		}
+		var missing []string
+		for _, name := range squashed {
+			if !applied[name] {
+				missing = append(missing, name)
+			}
+		}
+		switch len(missing) {
+		case 0:
+			applied[migration.Name] = true
+		case len(squashed):
+		default:
+			return nil, fmt.Errorf(
+				"baseline %s replaces migrations this database only partly applied, %s missing; apply them from a checkout before the squash, then migrate again",
+				migration.Name, strings.Join(missing, ", "),
+			)
+		}
+	}
+
+	var pending []migrations.Migration
+	for _, migration := range sorted {
+		if !applied[migration.Name] {
+			pending = append(pending, migration)
+		}
+	}
+
+	if !opts.AllowOutOfOrder {
+		for _, migration := range pending {
+			if migration.Version() < latest {
+				return nil, fmt.Errorf(
+					"migration %s is older than the latest applied migration (%s); run with --allow-out-of-order to apply it anyway",
+					migration.Name, latest,
+				)
+			}
+		}
+	}
+
+	return pending, nil
+}
+
+// Rollback reverts the last batch when steps is 0, otherwise the last steps
+// migrations, newest first, and returns the names that were reverted
+func Rollback(db *gorm.DB, steps int) ([]string, error) {
+	if err := EnsureHistoryTable(db); err != nil {
+		return nil, fmt.Errorf("creating %s table: %w", HistoryTable, err)
+	}
+
+	var records []MigrationRecord
+	if err := db.Order("batch DESC, id DESC").Find(&records).Error; err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	var targets []MigrationRecord
+	if steps <= 0 {
+		for _, record := range records {
+			if record.Batch != records[0].Batch {
+				break
+			}
+			targets = append(targets, record)
+		}
+	} else {
+		targets = records[:min(steps, len(records))]
+	}
+
+	return revert(db, targets)
+}
+
+// Reset reverts every applied migration
+func Reset(db *gorm.DB) ([]string, error) {
+	if err := EnsureHistoryTable(db); err != nil {
+		return nil, fmt.Errorf("creating %s table: %w", HistoryTable, err)
+	}
+
+	var records []MigrationRecord
+	if err := db.Order("batch DESC, id DESC").Find(&records).Error; err != nil {
+		return nil, err
+	}
+	return revert(db, records)
+}
+
+func revert(db *gorm.DB, records []MigrationRecord) ([]string, error) {
+	sorted, err := SortedMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	registered := map[string]migrations.Migration{}
+	for _, migration := range sorted {
+		registered[migration.Name] = migration
+	}
+
+	// check everything up front so a rollback does not stop half way
+	for _, record := range records {
+		migration, ok := registered[record.Name]
+		if !ok {
+			return nil, fmt.Errorf("migration %s is applied but not registered", record.Name)
+		}
+		if migration.Down == nil {
+			return nil, fmt.Errorf("migration %s has no Down function", record.Name)
+		}
+	}
+
+	var reverted []string
+	for _, record := range records {
+		fmt.Println("↩️ Rolling back:", record.Name)
+		if err := registered[record.Name].Down(db); err != nil {
+			return reverted, fmt.Errorf("%s: %w", record.Name, err)
+		}
+		if err := db.Delete(&MigrationRecord{}, record.ID).Error; err != nil {
+			return reverted, fmt.Errorf("removing %s from history: %w", record.Name, err)
+		}
+		reverted = append(reverted, record.Name)
+	}
+
+	return reverted, nil
+}
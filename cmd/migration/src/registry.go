@@ -2,103 +2,80 @@ package constructmigrations
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
-
-	"github.com/kataras/golog"
-	"golang.org/x/text/cases"
-	"golang.org/x/text/language"
 )
 
+// migrationEntry a migration of the migration_registry template
+type migrationEntry struct {
+	Name    string // Up20250226160158Users
+	Down    string // Down20250226160158Users, empty when there is none
+	version string
+}
+
 func UpdateRegistryMigrations() {
-	files, err := filepath.Glob("migrations/*.go")
+	funcs, err := gormFuncs(migrationsDir)
 	if err != nil {
 		fmt.Println("❌ Error reading migration files:", err)
 		return
 	}
 
-	migrationRegex := regexp.MustCompile(`func (Up\d+\w*)\(`)
-	var registryEntries []string
+	var entries []migrationEntry
+	downFuncs := map[string]bool{}
+	for _, name := range funcs {
+		if strings.HasPrefix(name, "Down") {
+			downFuncs[name] = true
+		}
+	}
 
-	for _, file := range files {
-		content, err := os.ReadFile(file)
-		if err != nil {
-			fmt.Println("❌ Error reading file:", file, err)
+	migrationName := regexp.MustCompile(`^Up(\d{14})`)
+	for _, name := range funcs {
+		match := migrationName.FindStringSubmatch(name)
+		if match == nil {
 			continue
 		}
-
-		matches := migrationRegex.FindAllStringSubmatch(string(content), -1)
-		for _, match := range matches {
-			if len(match) > 1 {
-				registryEntries = append(registryEntries, fmt.Sprintf("\t\"%s\": %s,", match[1], match[1]))
-			}
+		entry := migrationEntry{Name: name, version: match[1]}
+		if down := "Down" + strings.TrimPrefix(name, "Up"); downFuncs[down] {
+			entry.Down = down
+		} else {
+			fmt.Println("⚠️ No Down function found for", name, "- it cannot be rolled back")
 		}
+		entries = append(entries, entry)
 	}
 
-	if len(registryEntries) == 0 {
-		fmt.Println("⚠️ No migration functions found.")
-		return
-	}
-
-	registryContent := fmt.Sprintf(`package migrations
-
-import "gorm.io/gorm"
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].version != entries[j].version {
+			return entries[i].version < entries[j].version
+		}
+		return entries[i].Name < entries[j].Name
+	})
 
-var MigrationRegistry = map[string]func(*gorm.DB) error{
-%s
-}
-`, strings.Join(registryEntries, "\n"))
+	// still written when empty, a squash may have removed every Go migration
+	if len(entries) == 0 {
+		fmt.Println("⚠️ No migration functions found, writing an empty registry.")
+	}
 
-	err = os.WriteFile("migrations/registry.go", []byte(registryContent), 0644)
-	if err != nil {
+	if err := writeGoFile(filepath.Join(migrationsDir, "registry.go"), "migration_registry", entries); err != nil {
 		fmt.Println("❌ Error updating migrations/registry.go:", err)
+		return
 	}
 
 	fmt.Println("✅ Updated migrations/registry.go successfully!")
 }
 
-func updateModelRegistry() {
+func updateModelRegistry() error {
 	modelsDir := "internal/models"
-	files, err := filepath.Glob(modelsDir + "/*.go")
+	structs, err := structTypes(modelsDir)
 	if err != nil {
-		golog.Fatal("Error reading model files:", err)
+		return fmt.Errorf("reading model files: %w", err)
 	}
 
-	excludedFiles := map[string]bool{
-		"Registry": true,
-	}
-
-	var registryEntries []string
-	titleCase := cases.Title(language.English)
-
-	for _, file := range files {
-		base := filepath.Base(file)
-
-		name := strings.TrimSuffix(base, ".go")
-		structName := titleCase.String(strings.ReplaceAll(name, "_", " "))
-		structName = strings.ReplaceAll(structName, " ", "")
-
-		if excludedFiles[structName] {
-			continue
-		}
-
-		registryEntries = append(registryEntries, fmt.Sprintf("\tnew(%s),", structName))
-	}
-
-	registryContent := `package models
-
-var ModelRegistry = []interface{}{`
-	if len(registryEntries) > 0 {
-		registryContent += "\n" + strings.Join(registryEntries, "\n") + "\n"
-	}
-	registryContent += "}"
-
-	err = os.WriteFile(modelsDir+"/registry.go", []byte(registryContent), 0644)
-	if err != nil {
-		golog.Fatal("Error updating registry.go:", err)
+	if err := writeGoFile(filepath.Join(modelsDir, "registry.go"), "model_registry", structs); err != nil {
+		return fmt.Errorf("updating internal/models/registry.go: %w", err)
 	}
 
 	fmt.Println("✅ Updated internal/models/registry.go successfully!")
+	return nil
 }
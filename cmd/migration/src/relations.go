@@ -0,0 +1,185 @@
+package constructmigrations
+
+import (
+	"backends/pkg/inflection"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// relation kinds of TableRelation
+const (
+	BelongsTo  = "BelongsTo"
+	HasMany    = "HasMany"
+	ManyToMany = "ManyToMany"
+)
+
+// TableSchema the columns and foreign keys of one table
+type TableSchema struct {
+	Name        string
+	Columns     []Column
+	ForeignKeys []ForeignKey
+}
+
+// TableRelation a relation as seen from Table's model, ForeignKey, References,
+// JoinForeignKey and JoinReferences are column names with the meaning of the gorm tags of the same name
+type TableRelation struct {
+	Type           string
+	Field          string // Go field name on Table's model
+	Table          string
+	RelatedTable   string
+	ForeignKey     string
+	References     string
+	JoinTable      string
+	JoinForeignKey string
+	JoinReferences string
+	OnUpdate       string // referential actions of a BelongsTo foreign key
+	OnDelete       string
+}
+
+// modelExcludedTables tables of the migration tool itself, never turned into models
+var modelExcludedTables = map[string]bool{
+	"migrations": true,
+	"registry":   true,
+	HistoryTable: true,
+	LockTable:    true,
+}
+
+// LoadSchema the columns and foreign keys of every table in the current database
+func LoadSchema(db *gorm.DB) ([]TableSchema, error) {
+	tables, err := Tables(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var schemas []TableSchema
+	for _, table := range tables {
+		if modelExcludedTables[table] {
+			continue
+		}
+		columns, err := Columns(db, table)
+		if err != nil {
+			return nil, err
+		}
+		keys, err := ForeignKeys(db, table)
+		if err != nil {
+			return nil, err
+		}
+		schemas = append(schemas, TableSchema{Name: table, Columns: columns, ForeignKeys: keys})
+	}
+	return schemas, nil
+}
+
+// IsJoinTable true for a pure many-to-many table, made of exactly two single
+// column foreign keys and nothing else
+func IsJoinTable(table TableSchema) bool {
+	if len(table.ForeignKeys) != 2 || len(table.Columns) != 2 {
+		return false
+	}
+	for _, key := range table.ForeignKeys {
+		if len(key.Columns) != 1 {
+			return false
+		}
+	}
+	return table.ForeignKeys[0].Columns[0] != table.ForeignKeys[1].Columns[0]
+}
+
+// InferRelations the relations of every model table keyed by table name: the
+// belongs-to side of each foreign key, the has-many side on the referenced
+// table, and many-to-many on both sides of a join table
+func InferRelations(tables []TableSchema) map[string][]TableRelation {
+	relations := map[string][]TableRelation{}
+	used := map[string]map[string]bool{}
+	for _, table := range tables {
+		used[table.Name] = map[string]bool{}
+		for _, column := range table.Columns {
+			used[table.Name][inflection.Camelize(column.Name)] = true
+		}
+	}
+	add := func(relation TableRelation, fallbackSuffix string) {
+		if used[relation.Table] == nil {
+			return
+		}
+		if used[relation.Table][relation.Field] {
+			relation.Field += fallbackSuffix
+		}
+		used[relation.Table][relation.Field] = true
+		relations[relation.Table] = append(relations[relation.Table], relation)
+	}
+
+	for _, table := range tables {
+		if IsJoinTable(table) {
+			continue
+		}
+
+		keysTo := map[string]int{}
+		for _, key := range table.ForeignKeys {
+			keysTo[key.ReferencedTable]++
+		}
+
+		for _, key := range table.ForeignKeys {
+			if len(key.Columns) != 1 {
+				continue
+			}
+			foreignKey, references := key.Columns[0], key.ReferencedColumns[0]
+			ambiguous := keysTo[key.ReferencedTable] > 1 || key.ReferencedTable == table.Name
+			role := strings.TrimSuffix(strings.TrimSuffix(inflection.Camelize(foreignKey), "ID"), "Id")
+			if role == "" {
+				role = inflection.Camelize(foreignKey)
+			}
+
+			belongsTo := inflection.ModelName(key.ReferencedTable)
+			if ambiguous {
+				belongsTo = role
+			}
+			add(TableRelation{
+				Type:         BelongsTo,
+				Field:        belongsTo,
+				Table:        table.Name,
+				RelatedTable: key.ReferencedTable,
+				ForeignKey:   foreignKey,
+				References:   references,
+				OnUpdate:     key.OnUpdate,
+				OnDelete:     key.OnDelete,
+			}, "Ref")
+
+			hasMany := inflection.Camelize(table.Name)
+			if ambiguous {
+				hasMany += role
+			}
+			add(TableRelation{
+				Type:         HasMany,
+				Field:        hasMany,
+				Table:        key.ReferencedTable,
+				RelatedTable: table.Name,
+				ForeignKey:   foreignKey,
+				References:   references,
+			}, "By"+role)
+		}
+	}
+
+	for _, table := range tables {
+		if !IsJoinTable(table) {
+			continue
+		}
+		for i, key := range table.ForeignKeys {
+			other := table.ForeignKeys[1-i]
+			field := inflection.Camelize(other.ReferencedTable)
+			if key.ReferencedTable == other.ReferencedTable {
+				field = inflection.Plural(strings.TrimSuffix(strings.TrimSuffix(inflection.Camelize(other.Columns[0]), "ID"), "Id"))
+			}
+			add(TableRelation{
+				Type:           ManyToMany,
+				Field:          field,
+				Table:          key.ReferencedTable,
+				RelatedTable:   other.ReferencedTable,
+				ForeignKey:     key.ReferencedColumns[0],
+				References:     other.ReferencedColumns[0],
+				JoinTable:      table.Name,
+				JoinForeignKey: key.Columns[0],
+				JoinReferences: other.Columns[0],
+			}, "Via"+inflection.Camelize(table.Name))
+		}
+	}
+	return relations
+}
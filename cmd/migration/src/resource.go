@@ -0,0 +1,276 @@
+package constructmigrations
+
+import (
+	"backends/pkg/inflection"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+const (
+	repositoryDir  = "internal/repository"
+	controllersDir = "internal/controllers"
+	routesDir      = "internal/routes"
+)
+
+// storeEntry a store of the stores_registry template
+type storeEntry struct {
+	Field string // Orders
+	Store string // OrderStore
+}
+
+// resourceColumn a column written by the generated store
+type resourceColumn struct {
+	Name  string
+	Field string
+}
+
+// resourceData the data of the make:resource templates, for table order_items:
+// Model OrderItem, Plural OrderItems, Var orderItem, VarPlural orderItems,
+// Receiver oc, Label "order item", Title "Order item", JSONKey order_item, Path order-items
+type resourceData struct {
+	Model     string
+	Table     string
+	Plural    string
+	Var       string
+	VarPlural string
+	Receiver  string
+	Label     string
+	Title     string
+	JSONKey   string
+	Path      string
+
+	IDField       string
+	IDType        string
+	AutoIncrement bool
+	CreateColumns []resourceColumn
+	UpdateColumns []resourceColumn
+}
+
+// ToInt converts a primary key expression to the int used by the stores
+func (d resourceData) ToInt(expr string) string {
+	if d.IDType == "int" {
+		return expr
+	}
+	return "int(" + expr + ")"
+}
+
+// FromInt converts an int expression to the primary key type
+func (d resourceData) FromInt(expr string) string {
+	if d.IDType == "int" {
+		return expr
+	}
+	return d.IDType + "(" + expr + ")"
+}
+
+// FromInt64 converts an int64 expression, such as a last insert id, to the primary key type
+func (d resourceData) FromInt64(expr string) string {
+	if d.IDType == "int64" {
+		return expr
+	}
+	return d.IDType + "(" + expr + ")"
+}
+
+func (d resourceData) ColumnList(columns []resourceColumn) string {
+	var names []string
+	for _, column := range columns {
+		names = append(names, fmt.Sprintf("%q", column.Name))
+	}
+	return "[]string{" + strings.Join(names, ", ") + "}"
+}
+
+func (d resourceData) ValueList(columns []resourceColumn) string {
+	var values []string
+	for _, column := range columns {
+		values = append(values, d.Var+"."+column.Field)
+	}
+	return "[]interface{}{" + strings.Join(values, ", ") + "}"
+}
+
+// pluralName the plural of a model name, used for store fields and handler names
+func pluralName(model string) string {
+	return inflection.Plural(model)
+}
+
+func lowerFirst(name string) string {
+	if name == "" {
+		return name
+	}
+	name = strings.ToLower(name[:1]) + name[1:]
+	if token.IsKeyword(name) {
+		name += "Item"
+	}
+	return name
+}
+
+func newResourceData(table TableSchema) (resourceData, error) {
+	model := inflection.ModelName(table.Name)
+	label := strings.ReplaceAll(toSnakeCase(model), "_", " ")
+	data := resourceData{
+		Model:     model,
+		Table:     table.Name,
+		Plural:    pluralName(model),
+		Var:       lowerFirst(model),
+		VarPlural: lowerFirst(pluralName(model)),
+		Receiver:  strings.ToLower(model[:1]) + "c",
+		Label:     label,
+		Title:     strings.ToUpper(label[:1]) + label[1:],
+		JSONKey:   toSnakeCase(model),
+		Path:      strings.ReplaceAll(table.Name, "_", "-"),
+	}
+
+	var primaryKeys []Column
+	for _, column := range table.Columns {
+		if column.PrimaryKey {
+			primaryKeys = append(primaryKeys, column)
+		}
+	}
+	if len(primaryKeys) != 1 {
+		return data, fmt.Errorf("table %s needs exactly one primary key column, found %d", table.Name, len(primaryKeys))
+	}
+	primaryKey := primaryKeys[0]
+	idType, _ := goColumnType(primaryKey)
+	if !strings.HasPrefix(idType, "int") && !strings.HasPrefix(idType, "uint") {
+		return data, fmt.Errorf("table %s: primary key %s must be an integer, got %s", table.Name, primaryKey.Name, primaryKey.ColumnType)
+	}
+	data.IDField = inflection.Camelize(primaryKey.Name)
+	data.IDType = idType
+	data.AutoIncrement = primaryKey.AutoIncrement
+
+	for _, column := range table.Columns {
+		resourceColumn := resourceColumn{Name: column.Name, Field: inflection.Camelize(column.Name)}
+		if !column.PrimaryKey {
+			data.UpdateColumns = append(data.UpdateColumns, resourceColumn)
+		}
+		if !column.PrimaryKey || !column.AutoIncrement {
+			data.CreateColumns = append(data.CreateColumns, resourceColumn)
+		}
+	}
+	return data, nil
+}
+
+// CreateResource scaffolds the model, store, controller, routes and an HTTP test
+// for a table, files that already exist are left untouched
+func CreateResource(db *gorm.DB, tableName string) error {
+	tables, err := LoadSchema(db)
+	if err != nil {
+		return err
+	}
+
+	var table *TableSchema
+	for i := range tables {
+		if tables[i].Name == tableName {
+			table = &tables[i]
+		}
+	}
+	if table == nil {
+		return fmt.Errorf("table %s not found", tableName)
+	}
+	if IsJoinTable(*table) {
+		return fmt.Errorf("table %s is a join table, it has no resource of its own", tableName)
+	}
+
+	data, err := newResourceData(*table)
+	if err != nil {
+		return err
+	}
+
+	modelFile := fmt.Sprintf("internal/models/%s.go", data.Model)
+	if fileExists(modelFile) {
+		fmt.Println("⚠️ Using existing model:", modelFile)
+	} else {
+		if err := writeModelFile(*table, InferRelations(tables)[table.Name]); err != nil {
+			return err
+		}
+		if err := updateModelRegistry(); err != nil {
+			return err
+		}
+	}
+
+	files := []struct {
+		path, name string
+	}{
+		{filepath.Join(repositoryDir, toSnakeCase(data.Model)+"_store.go"), "store"},
+		{filepath.Join(controllersDir, data.Model+"Controller.go"), "controller"},
+		{filepath.Join(routesDir, table.Name+"_routes.go"), "routes"},
+		{filepath.Join(routesDir, table.Name+"_routes_test.go"), "routes_test"},
+	}
+	for _, file := range files {
+		if fileExists(file.path) {
+			fmt.Println("⚠️ File already exists, skipping:", file.path)
+			continue
+		}
+		if err := writeGoFile(file.path, file.name, data); err != nil {
+			return err
+		}
+		fmt.Println("✅ Created", file.path)
+	}
+
+	if err := UpdateStoresRegistry(); err != nil {
+		return err
+	}
+	return UpdateResourcesRegistry()
+}
+
+// UpdateStoresRegistry regenerates internal/repository/stores.go from the XStore
+// interfaces that have NewSQLXStore and NewMemoryXStore constructors
+func UpdateStoresRegistry() error {
+	interfaces, err := interfaceTypes(repositoryDir)
+	if err != nil {
+		return err
+	}
+	constructors, err := declaredFuncs(repositoryDir, func(fn *ast.FuncDecl) bool { return true })
+	if err != nil {
+		return err
+	}
+	declared := map[string]bool{}
+	for _, name := range constructors {
+		declared[name] = true
+	}
+
+	var entries []storeEntry
+	for _, name := range interfaces {
+		if !strings.HasSuffix(name, "Store") || name == "Store" {
+			continue
+		}
+		if !declared["NewSQL"+name] || !declared["NewMemory"+name] {
+			fmt.Println("⚠️ Skipping", name, "- it needs NewSQL"+name+" and NewMemory"+name+" constructors")
+			continue
+		}
+		entries = append(entries, storeEntry{Field: pluralName(strings.TrimSuffix(name, "Store")), Store: name})
+	}
+
+	if err := writeGoFile(filepath.Join(repositoryDir, "stores.go"), "stores_registry", entries); err != nil {
+		return err
+	}
+	fmt.Println("✅ Updated internal/repository/stores.go successfully!")
+	return nil
+}
+
+// UpdateResourcesRegistry regenerates internal/routes/resources.go from the
+// registerXRoutes functions of the routes package
+func UpdateResourcesRegistry() error {
+	funcs, err := declaredFuncs(routesDir, func(fn *ast.FuncDecl) bool {
+		name := fn.Name.Name
+		return strings.HasPrefix(name, "register") && strings.HasSuffix(name, "Routes") && len(name) > len("registerRoutes")
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := writeGoFile(filepath.Join(routesDir, "resources.go"), "resources_registry", funcs); err != nil {
+		return err
+	}
+	fmt.Println("✅ Updated internal/routes/resources.go successfully!")
+	return nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
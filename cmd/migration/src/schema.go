@@ -0,0 +1,106 @@
+package constructmigrations
+
+import (
+	"backends/migrations"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// SchemaDumpFile the schema written by schema:dump, meant to be committed with
+// the migrations it replaces
+var SchemaDumpFile = filepath.Join(migrationsDir, "schema", "schema.sql")
+
+// schemaVersionPrefix header line holding the newest migration included in a dump
+const schemaVersionPrefix = "-- schema version: "
+
+// DumpSchema writes the DDL of every table and the rows of the history table to
+// path, so a database can be rebuilt without replaying the migrations. It
+// returns the newest migration included in the dump.
+func DumpSchema(db *gorm.DB, path string) (string, error) {
+	if !db.Migrator().HasTable(HistoryTable) {
+		return "", fmt.Errorf("no %s table, run the migrations before dumping the schema", HistoryTable)
+	}
+	records, err := AppliedMigrations(db)
+	if err != nil {
+		return "", err
+	}
+	if len(records) == 0 {
+		return "", errors.New("no migration has been applied yet, nothing to dump")
+	}
+
+	latest := records[0].Name
+	for _, record := range records {
+		if (migrations.Migration{Name: record.Name}).Version() > (migrations.Migration{Name: latest}).Version() {
+			latest = record.Name
+		}
+	}
+
+	tables, err := userTables(db)
+	if err != nil {
+		return "", err
+	}
+
+	var w bytes.Buffer
+	fmt.Fprintln(&w, schemaVersionPrefix+latest)
+	fmt.Fprintln(&w, "-- Load with --action=schema:load, newer migrations run afterwards")
+	if err := DumpDatabase(db, &w, DumpOptions{Tables: tables, ResetAutoIncrement: true}); err != nil {
+		return "", err
+	}
+	fmt.Fprintln(&w)
+	if err := dumpRows(db, &w, HistoryTable); err != nil {
+		return "", fmt.Errorf("dumping rows of %s: %w", HistoryTable, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return "", err
+	}
+	return latest, os.WriteFile(path, w.Bytes(), 0644)
+}
+
+// LoadSchemaDump replays a schema dump and returns the newest migration it
+// includes. The dump drops and recreates its own tables, tables it does not
+// know about are left alone.
+func LoadSchemaDump(db *gorm.DB, path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	firstLine, _, _ := strings.Cut(string(content), "\n")
+	version, ok := strings.CutPrefix(strings.TrimSpace(firstLine), strings.TrimSpace(schemaVersionPrefix))
+	if !ok {
+		return "", fmt.Errorf("%s is not a schema dump, it has no %q header", path, strings.TrimSpace(schemaVersionPrefix))
+	}
+
+	if err := sqlFileRunner(path)(db); err != nil {
+		return "", fmt.Errorf("loading %s: %w", path, err)
+	}
+	return strings.TrimSpace(version), nil
+}
+
+// EmptyDatabase true when the database has no tables besides the migration lock
+func EmptyDatabase(db *gorm.DB) (bool, error) {
+	tables, err := userTables(db)
+	return len(tables) == 0, err
+}
+
+// userTables every table of the database except the migration lock
+func userTables(db *gorm.DB) ([]string, error) {
+	all, err := Tables(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var tables []string
+	for _, table := range all {
+		if table != LockTable {
+			tables = append(tables, table)
+		}
+	}
+	return tables, nil
+}
@@ -0,0 +1,93 @@
+package constructmigrations
+
+import (
+	"backends/pkg/inflection"
+	"backends/seeders"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+const seedersDir = "seeders"
+
+// RunSeeders runs every registered seeder in registry order, or only the one
+// named class, and returns the names that ran
+func RunSeeders(db *gorm.DB, class string) ([]string, error) {
+	var selected []seeders.Seeder
+	for _, seeder := range seeders.SeederRegistry {
+		if class == "" || seeder.Name == class {
+			selected = append(selected, seeder)
+		}
+	}
+	if class != "" && len(selected) == 0 {
+		return nil, fmt.Errorf("seeder %q is not registered", class)
+	}
+
+	var ran []string
+	for _, seeder := range selected {
+		fmt.Println("🌱 Seeding:", seeder.Name)
+		if err := seeder.Run(db); err != nil {
+			return ran, fmt.Errorf("%s: %w", seeder.Name, err)
+		}
+		ran = append(ran, seeder.Name)
+	}
+	return ran, nil
+}
+
+// SeederName normalizes "roles", "Roles" or "RolesSeeder" to "RolesSeeder"
+func SeederName(name string) string {
+	name = inflection.Camelize(name)
+	if !strings.HasSuffix(name, "Seeder") {
+		name += "Seeder"
+	}
+	return name
+}
+
+func CreateSeeder(class string) {
+	name := SeederName(class)
+	base := strings.TrimSuffix(name, "Seeder")
+	filename := filepath.Join(seedersDir, toSnakeCase(base)+"_seeder.go")
+
+	if _, err := os.Stat(filename); err == nil {
+		fmt.Println("⚠️ Seeder file already exists:", filename)
+		return
+	}
+
+	data := seederData{Name: name, Model: base}
+	if err := writeGoFile(filename, "seeder", data); err != nil {
+		fmt.Println("❌ Error creating seeder file:", err)
+		return
+	}
+
+	fmt.Println("✅ Seeder file created:", filename)
+	UpdateRegistrySeeders()
+}
+
+func UpdateRegistrySeeders() {
+	funcs, err := gormFuncs(seedersDir)
+	if err != nil {
+		fmt.Println("❌ Error reading seeder files:", err)
+		return
+	}
+
+	var names []string
+	for _, name := range funcs {
+		if strings.HasSuffix(name, "Seeder") {
+			names = append(names, name)
+		}
+	}
+
+	if err := writeGoFile(filepath.Join(seedersDir, "registry.go"), "seeder_registry", names); err != nil {
+		fmt.Println("❌ Error updating seeders/registry.go:", err)
+		return
+	}
+
+	fmt.Println("✅ Updated seeders/registry.go successfully!")
+}
+
+func toSnakeCase(name string) string {
+	return inflection.Underscore(name)
+}
@@ -0,0 +1,125 @@
+package constructmigrations
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// parseGoDir parses the non-test Go files of dir, skipping the generated registry.go
+func parseGoDir(dir string) ([]*ast.File, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	var parsed []*ast.File
+	for _, file := range files {
+		base := filepath.Base(file)
+		if base == "registry.go" || strings.HasSuffix(base, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, f)
+	}
+	return parsed, nil
+}
+
+// declaredFuncs names of the top level functions in dir accepted by match
+func declaredFuncs(dir string, match func(fn *ast.FuncDecl) bool) ([]string, error) {
+	files, err := parseGoDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if ok && fn.Recv == nil && match(fn) {
+				names = append(names, fn.Name.Name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// declaredTypes names of the exported, non generic types in dir accepted by match
+func declaredTypes(dir string, match func(spec *ast.TypeSpec) bool) ([]string, error) {
+	files, err := parseGoDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				if typeSpec.Name.IsExported() && typeSpec.TypeParams == nil && match(typeSpec) {
+					names = append(names, typeSpec.Name.Name)
+				}
+			}
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// gormFuncs names of the top level `func Name(db *gorm.DB) error` declarations in dir
+func gormFuncs(dir string) ([]string, error) {
+	return declaredFuncs(dir, func(fn *ast.FuncDecl) bool { return isGormFunc(fn.Type) })
+}
+
+func isGormFunc(fn *ast.FuncType) bool {
+	if fn.Params == nil || len(fn.Params.List) != 1 || len(fn.Params.List[0].Names) > 1 {
+		return false
+	}
+	if fn.Results == nil || len(fn.Results.List) != 1 || len(fn.Results.List[0].Names) > 1 {
+		return false
+	}
+
+	star, ok := fn.Params.List[0].Type.(*ast.StarExpr)
+	if !ok {
+		return false
+	}
+	sel, ok := star.X.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	pkg, ok := sel.X.(*ast.Ident)
+	if !ok || pkg.Name != "gorm" || sel.Sel.Name != "DB" {
+		return false
+	}
+
+	result, ok := fn.Results.List[0].Type.(*ast.Ident)
+	return ok && result.Name == "error"
+}
+
+// structTypes names of the exported struct types declared in dir
+func structTypes(dir string) ([]string, error) {
+	return declaredTypes(dir, func(spec *ast.TypeSpec) bool {
+		_, ok := spec.Type.(*ast.StructType)
+		return ok
+	})
+}
+
+// interfaceTypes names of the exported interface types declared in dir
+func interfaceTypes(dir string) ([]string, error) {
+	return declaredTypes(dir, func(spec *ast.TypeSpec) bool {
+		_, ok := spec.Type.(*ast.InterfaceType)
+		return ok
+	})
+}
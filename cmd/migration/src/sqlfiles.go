@@ -0,0 +1,221 @@
+package constructmigrations
+
+import (
+	"backends/migrations"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const migrationsDir = "migrations"
+
+// LoadSQLMigrations discovers migrations/<timestamp>_<name>.up.sql files and
+// their optional .down.sql pair. The migration name is the file name without
+// the .up.sql suffix.
+func LoadSQLMigrations() ([]migrations.Migration, error) {
+	files, err := filepath.Glob(filepath.Join(migrationsDir, "*.up.sql"))
+	if err != nil {
+		return nil, err
+	}
+
+	var result []migrations.Migration
+	for _, upFile := range files {
+		name := strings.TrimSuffix(filepath.Base(upFile), ".up.sql")
+		migration := migrations.Migration{Name: name}
+		if migration.Version() == "" {
+			fmt.Println("⚠️ Skipping SQL migration without timestamp prefix:", upFile)
+			continue
+		}
+
+		migration.Up = sqlFileRunner(upFile)
+		downFile := strings.TrimSuffix(upFile, ".up.sql") + ".down.sql"
+		if _, err := os.Stat(downFile); err == nil {
+			migration.Down = sqlFileRunner(downFile)
+		}
+		result = append(result, migration)
+	}
+
+	return result, nil
+}
+
+func isSQLMigration(name string) bool {
+	return !strings.HasPrefix(name, "Up")
+}
+
+// sqlFileRunner executes the statements of a SQL file, inside a transaction
+// on engines with transactional DDL. MySQL commits DDL implicitly, so there
+// the statements run one by one on a single connection, which keeps session
+// settings such as SET FOREIGN_KEY_CHECKS = 0 in effect for the whole file.
+func sqlFileRunner(path string) func(*gorm.DB) error {
+	return func(db *gorm.DB) error {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		statements, err := SplitSQLStatements(string(content))
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+
+		run := func(tx *gorm.DB) error {
+			for _, statement := range statements {
+				if err := tx.Exec(statement).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		switch {
+		case isDryRun(db):
+			return run(db)
+		case db.Dialector.Name() == "mysql":
+			return db.Connection(run)
+		}
+		return db.Transaction(run)
+	}
+}
+
+// SplitSQLStatements splits a SQL script on semicolons outside quotes,
+// comments and dollar-quoted bodies. Statements containing semicolons of their
+// own (triggers, procedures) can be wrapped in
+//
+//	-- +migrate StatementBegin
+//	...
+//	-- +migrate StatementEnd
+func SplitSQLStatements(script string) ([]string, error) {
+	var statements []string
+	var current strings.Builder
+	inBlock := false
+
+	flush := func() {
+		statement := strings.TrimSpace(current.String())
+		statement = strings.TrimSpace(strings.TrimSuffix(statement, ";"))
+		if statement != "" && !onlyComments(statement) {
+			statements = append(statements, statement)
+		}
+		current.Reset()
+	}
+
+	// closing is the terminator of the quote, dollar-quoted body or block
+	// comment we are in, carried across lines
+	closing := ""
+	for _, line := range strings.SplitAfter(script, "\n") {
+		marker := strings.ToLower(strings.TrimSpace(line))
+		if closing == "" && marker == "-- +migrate statementbegin" {
+			flush()
+			inBlock = true
+			continue
+		}
+		if closing == "" && marker == "-- +migrate statementend" {
+			if !inBlock {
+				return nil, fmt.Errorf("StatementEnd without StatementBegin")
+			}
+			flush()
+			inBlock = false
+			continue
+		}
+		if inBlock {
+			current.WriteString(line)
+			continue
+		}
+
+		for i := 0; i < len(line); i++ {
+			ch := line[i]
+
+			if closing != "" {
+				switch {
+				case strings.HasPrefix(line[i:], closing):
+					current.WriteString(closing)
+					i += len(closing) - 1
+					closing = ""
+				case ch == '\\' && (closing == "'" || closing == "\"") && i+1 < len(line):
+					current.WriteString(line[i : i+2])
+					i++
+				default:
+					current.WriteByte(ch)
+				}
+				continue
+			}
+
+			switch {
+			case strings.HasPrefix(line[i:], "--"):
+				current.WriteString(line[i:])
+				i = len(line)
+			case strings.HasPrefix(line[i:], "/*"):
+				closing = "*/"
+				current.WriteString("/*")
+				i++
+			case ch == '\'' || ch == '"' || ch == '`':
+				closing = string(ch)
+				current.WriteByte(ch)
+			case ch == '$' && dollarTag(line[i:]) != "":
+				closing = dollarTag(line[i:])
+				current.WriteString(closing)
+				i += len(closing) - 1
+			case ch == ';':
+				current.WriteByte(ch)
+				flush()
+			default:
+				current.WriteByte(ch)
+			}
+		}
+	}
+
+	if inBlock {
+		return nil, fmt.Errorf("StatementBegin without StatementEnd")
+	}
+	if closing != "" {
+		return nil, fmt.Errorf("unterminated %s", closing)
+	}
+	flush()
+	return statements, nil
+}
+
+// dollarTag returns the Postgres dollar quote ($$ or $tag$) at the start of s
+func dollarTag(s string) string {
+	for i := 1; i < len(s); i++ {
+		ch := s[i]
+		if ch == '$' {
+			return s[:i+1]
+		}
+		if !(ch == '_' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || i > 1 && ch >= '0' && ch <= '9') {
+			return ""
+		}
+	}
+	return ""
+}
+
+func onlyComments(statement string) bool {
+	for _, line := range strings.Split(statement, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "--") {
+			return false
+		}
+	}
+	return true
+}
+
+// CreateSQLMigration scaffolds an empty .up.sql / .down.sql pair
+func CreateSQLMigration(name string) {
+	timestamp := time.Now().Format("20060102150405")
+	base := filepath.Join(migrationsDir, fmt.Sprintf("%s_%s", timestamp, name))
+
+	upContent := fmt.Sprintf("-- Migration: %s_%s (up)\n-- Write the SQL to apply here.\n", timestamp, name)
+	downContent := fmt.Sprintf("-- Migration: %s_%s (down)\n-- Write the SQL that reverts the up migration here.\n", timestamp, name)
+
+	if err := os.WriteFile(base+".up.sql", []byte(upContent), 0644); err != nil {
+		fmt.Println("❌ Error creating migration file:", err)
+		return
+	}
+	if err := os.WriteFile(base+".down.sql", []byte(downContent), 0644); err != nil {
+		fmt.Println("❌ Error creating migration file:", err)
+		return
+	}
+
+	fmt.Println("✅ Migration files created:", base+".up.sql", base+".down.sql")
+}
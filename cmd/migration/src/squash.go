@@ -0,0 +1,226 @@
+package constructmigrations
+
+import (
+	"backends/internal/storage/query"
+	"backends/migrations"
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// squashedPrefix header line of a baseline migration listing the migrations it replaces
+const squashedPrefix = "-- squashed: "
+
+var versionPattern = regexp.MustCompile(`^\d{14}$`)
+
+// Squash replaces every migration up to and including version before with a
+// <before>_baseline SQL migration holding the schema of db, which must have
+// applied exactly those migrations. It returns the baseline name and the
+// migrations it replaced.
+func Squash(db *gorm.DB, before string) (string, []string, error) {
+	if !versionPattern.MatchString(before) {
+		return "", nil, fmt.Errorf("--before must be a migration timestamp such as 20250226160158, got %q", before)
+	}
+
+	sorted, err := SortedMigrations()
+	if err != nil {
+		return "", nil, err
+	}
+	var squashed []string
+	for _, migration := range sorted {
+		if migration.Version() <= before {
+			squashed = append(squashed, migration.Name)
+		}
+	}
+	if len(squashed) == 0 {
+		return "", nil, fmt.Errorf("no migrations up to %s", before)
+	}
+
+	if err := EnsureHistoryTable(db); err != nil {
+		return "", nil, fmt.Errorf("creating %s table: %w", HistoryTable, err)
+	}
+	records, err := AppliedMigrations(db)
+	if err != nil {
+		return "", nil, err
+	}
+	applied := map[string]bool{}
+	for _, record := range records {
+		applied[record.Name] = true
+		if (migrations.Migration{Name: record.Name}).Version() > before {
+			return "", nil, fmt.Errorf("migration %s newer than %s is applied, squash from a database migrated exactly up to %s", record.Name, before, before)
+		}
+	}
+	for _, name := range squashed {
+		if !applied[name] {
+			return "", nil, fmt.Errorf("migration %s is not applied, migrate the database up to %s before squashing", name, before)
+		}
+	}
+
+	tables, err := userTables(db)
+	if err != nil {
+		return "", nil, err
+	}
+	var schemaTables []string
+	for _, table := range tables {
+		if table != HistoryTable {
+			schemaTables = append(schemaTables, table)
+		}
+	}
+
+	var up bytes.Buffer
+	fmt.Fprintln(&up, squashedPrefix+strings.Join(squashed, ","))
+	// without DROP statements the baseline fails on existing tables instead of emptying them
+	if err := DumpDatabase(db, &up, DumpOptions{Tables: schemaTables, ResetAutoIncrement: true, SkipDrop: true}); err != nil {
+		return "", nil, err
+	}
+
+	var down bytes.Buffer
+	fmt.Fprintln(&down, "SET FOREIGN_KEY_CHECKS = 0;")
+	for i := len(schemaTables) - 1; i >= 0; i-- {
+		fmt.Fprintf(&down, "DROP TABLE IF EXISTS %s;\n", query.QuoteIdentifier(schemaTables[i]))
+	}
+	fmt.Fprintln(&down, "SET FOREIGN_KEY_CHECKS = 1;")
+
+	name := before + "_baseline"
+	if err := writeBaseline(name, squashed, up.Bytes(), down.Bytes()); err != nil {
+		return "", nil, err
+	}
+
+	if _, err := RecordSquashedBaselines(db); err != nil {
+		return name, squashed, err
+	}
+	return name, squashed, nil
+}
+
+// writeBaseline replaces the files of the squashed migrations with the
+// baseline SQL migration name and regenerates the Go registry
+func writeBaseline(name string, squashed []string, up, down []byte) error {
+	for _, migration := range squashed {
+		if err := removeMigrationFiles(migration); err != nil {
+			return err
+		}
+		fmt.Println("🗑️ Removed migration:", migration)
+	}
+
+	base := filepath.Join(migrationsDir, name)
+	if err := os.WriteFile(base+".up.sql", up, 0644); err != nil {
+		return err
+	}
+	if err := os.WriteFile(base+".down.sql", down, 0644); err != nil {
+		return err
+	}
+	UpdateRegistryMigrations()
+	return nil
+}
+
+// removeMigrationFiles deletes the source files of a Go or SQL migration
+func removeMigrationFiles(name string) error {
+	var files []string
+	if isSQLMigration(name) {
+		files = []string{filepath.Join(migrationsDir, name+".up.sql"), filepath.Join(migrationsDir, name+".down.sql")}
+	} else {
+		version := migrations.Migration{Name: name}.Version()
+		matches, err := filepath.Glob(filepath.Join(migrationsDir, version+"_*.go"))
+		if err != nil {
+			return err
+		}
+		files = matches
+	}
+
+	for _, file := range files {
+		if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("removing %s: %w", file, err)
+		}
+	}
+	return nil
+}
+
+// SquashedMigrations the migrations a baseline replaces, nil when name is not a baseline
+func SquashedMigrations(name string) []string {
+	if !isSQLMigration(name) {
+		return nil
+	}
+	file, err := os.Open(filepath.Join(migrationsDir, name+".up.sql"))
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	if !scanner.Scan() {
+		return nil
+	}
+	list, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), strings.TrimSpace(squashedPrefix))
+	if !ok {
+		return nil
+	}
+	var names []string
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// RecordSquashedBaselines marks a baseline as applied on a database that ran
+// every migration it replaces, in the batch of the newest of them, and drops
+// the history rows of the replaced migrations. It returns the recorded baselines.
+func RecordSquashedBaselines(db *gorm.DB) ([]string, error) {
+	sqlMigrations, err := LoadSQLMigrations()
+	if err != nil {
+		return nil, err
+	}
+	records, err := AppliedMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+	applied := map[string]MigrationRecord{}
+	for _, record := range records {
+		applied[record.Name] = record
+	}
+
+	var recorded []string
+	for _, migration := range sqlMigrations {
+		if _, ok := applied[migration.Name]; ok {
+			continue
+		}
+		squashed := SquashedMigrations(migration.Name)
+		if len(squashed) == 0 || !allApplied(squashed, applied) {
+			continue
+		}
+
+		batch := 0
+		for _, name := range squashed {
+			batch = max(batch, applied[name].Batch)
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("name IN ?", squashed).Delete(&MigrationRecord{}).Error; err != nil {
+				return err
+			}
+			return RecordMigration(tx, migration.Name, batch, MigrationChecksum(migration.Name))
+		})
+		if err != nil {
+			return recorded, fmt.Errorf("recording baseline %s: %w", migration.Name, err)
+		}
+		fmt.Println("📌 Marked baseline as applied:", migration.Name)
+		recorded = append(recorded, migration.Name)
+	}
+	return recorded, nil
+}
+
+func allApplied(names []string, applied map[string]MigrationRecord) bool {
+	for _, name := range names {
+		if _, ok := applied[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,34 @@
+package constructmigrations
+
+import (
+	"os/exec"
+	"testing"
+)
+
+// TestSquashAllGoMigrationsBuilds replaces every registered migration with a
+// baseline and checks the migrations package and the migration tool still
+// compile. The schema dump itself needs mysql, so the baseline SQL is given.
+func TestSquashAllGoMigrationsBuilds(t *testing.T) {
+	goBin, dir := chdirModuleCopy(t)
+
+	sorted, err := SortedMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var squashed []string
+	for _, migration := range sorted {
+		squashed = append(squashed, migration.Name)
+	}
+	name := sorted[len(sorted)-1].Version() + "_baseline"
+
+	up := []byte(squashedPrefix + "...\nCREATE TABLE roles (id INTEGER PRIMARY KEY);\n")
+	if err := writeBaseline(name, squashed, up, []byte("DROP TABLE roles;\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	build := exec.Command(goBin, "build", "./migrations", "./cmd/migration")
+	build.Dir = dir
+	if output, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("squashed tree does not compile: %v\n%s", err, output)
+	}
+}
@@ -0,0 +1,98 @@
+package constructmigrations
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type MigrationStatus struct {
+	Name      string
+	Applied   bool
+	Missing   bool // applied but no longer registered
+	Batch     int
+	AppliedAt time.Time
+	Modified  bool // source checksum changed after it was applied
+}
+
+// Status every registered migration with its applied state, plus applied
+// migrations that are no longer registered. It only reads, a missing history
+// table means nothing was applied yet.
+func Status(db *gorm.DB) ([]MigrationStatus, error) {
+	var records []MigrationRecord
+	if db.Migrator().HasTable(HistoryTable) {
+		var err error
+		if records, err = AppliedMigrations(db); err != nil {
+			return nil, err
+		}
+	}
+	applied := map[string]MigrationRecord{}
+	for _, record := range records {
+		applied[record.Name] = record
+	}
+
+	sorted, err := SortedMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []MigrationStatus
+	registered := map[string]bool{}
+	for _, migration := range sorted {
+		registered[migration.Name] = true
+
+		status := MigrationStatus{Name: migration.Name}
+		if record, ok := applied[migration.Name]; ok {
+			checksum := MigrationChecksum(migration.Name)
+			status.Applied = true
+			status.Batch = record.Batch
+			status.AppliedAt = record.AppliedAt
+			status.Modified = record.Checksum != "" && checksum != "" && record.Checksum != checksum
+		}
+		statuses = append(statuses, status)
+	}
+
+	for _, record := range records {
+		if !registered[record.Name] {
+			statuses = append(statuses, MigrationStatus{
+				Name:      record.Name,
+				Applied:   true,
+				Missing:   true,
+				Batch:     record.Batch,
+				AppliedAt: record.AppliedAt,
+			})
+		}
+	}
+
+	return statuses, nil
+}
+
+func PrintStatus(w io.Writer, statuses []MigrationStatus) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "MIGRATION\tSTATUS\tBATCH\tAPPLIED AT\tCHECKSUM")
+
+	pending := 0
+	for _, status := range statuses {
+		state, batch, appliedAt, checksum := "Pending", "-", "-", ""
+		if status.Applied {
+			state = "Applied"
+			batch = fmt.Sprint(status.Batch)
+			appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+		} else {
+			pending++
+		}
+		if status.Missing {
+			state = "Applied (missing)"
+		}
+		if status.Modified {
+			checksum = "changed"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", status.Name, state, batch, appliedAt, checksum)
+	}
+	tw.Flush()
+
+	fmt.Fprintf(w, "\n%d migration(s), %d pending\n", len(statuses), pending)
+}
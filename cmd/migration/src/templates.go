@@ -0,0 +1,108 @@
+package constructmigrations
+
+import (
+	"backends/pkg/inflection"
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"go/format"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/template"
+)
+
+// TemplatesDir project directory whose <name>.tmpl files replace the embedded
+// defaults of the same name, see templates/README.md for the names and their data
+const TemplatesDir = ".blueprint/templates"
+
+//go:embed templates/*.tmpl
+var defaultTemplates embed.FS
+
+// templateFuncs helpers available to every template
+var templateFuncs = template.FuncMap{
+	"snake":      toSnakeCase,
+	"camel":      inflection.Camelize,
+	"lowerFirst": lowerFirst,
+	"plural":     pluralName,
+	"quote":      strconv.Quote,
+}
+
+// modelData data of the model template
+type modelData struct {
+	Name    string   // struct name, Order
+	Table   string   // set when the default naming would not map Name to the table
+	Imports []string // packages the field types need
+	Enums   []enumType
+	Fields  []structField
+}
+
+// migrationData data of the migration template
+type migrationData struct {
+	UpName     string // Up20250226160158Orders
+	DownName   string // Down20250226160158Orders
+	StructName string // Orders
+	Table      string // orders
+}
+
+// diffMigrationData data of the diff_migration template
+type diffMigrationData struct {
+	Imports  []string
+	UpName   string
+	DownName string
+	Up       migrationBody
+	Down     migrationBody
+}
+
+// seederData data of the seeder template
+type seederData struct {
+	Name  string // RolesSeeder
+	Model string // Roles
+}
+
+// loadTemplate the project override of name if there is one, the embedded default otherwise
+func loadTemplate(name string) (*template.Template, error) {
+	text, err := os.ReadFile(filepath.Join(TemplatesDir, name+".tmpl"))
+	if errors.Is(err, fs.ErrNotExist) {
+		text, err = defaultTemplates.ReadFile("templates/" + name + ".tmpl")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading %s template: %w", name, err)
+	}
+
+	tmpl, err := template.New(name).Funcs(templateFuncs).Parse(string(text))
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s template: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+// renderGo executes a source template and gofmts the result
+func renderGo(name string, data interface{}) ([]byte, error) {
+	tmpl, err := loadTemplate(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("rendering %s template: %w", name, err)
+	}
+
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting %s: %w", name, err)
+	}
+	return source, nil
+}
+
+// writeGoFile renders a source template into filename
+func writeGoFile(filename, name string, data interface{}) error {
+	source, err := renderGo(name, data)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, source, 0644)
+}
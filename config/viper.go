@@ -2,34 +2,68 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"os"
+	"strings"
 
 	"github.com/spf13/viper"
 )
 
+// DefaultConnection name of the connection configured by the unprefixed DB_* variables
+const DefaultConnection = "main"
+
 type EnvStructs struct {
-	DB_HOST     string `mapstructure:"DB_HOST"`
-	DB_PORT     string `mapstructure:"DB_PORT"`
-	DB_DATABASE string `mapstructure:"DB_DATABASE"`
-	DB_USER     string `mapstructure:"DB_USER"`
-	DB_PASSWORD string `mapstructure:"DB_PASSWORD"`
-	PORT        string `mapstructure:"PORT"`
-	APP_URL     string `mapstructure:"APP_URL"`
+	GO_ENV         string `mapstructure:"GO_ENV"`
+	DB_DRIVER      string `mapstructure:"DB_DRIVER"`
+	DB_HOST        string `mapstructure:"DB_HOST"`
+	DB_PORT        string `mapstructure:"DB_PORT"`
+	DB_DATABASE    string `mapstructure:"DB_DATABASE"`
+	DB_USER        string `mapstructure:"DB_USER"`
+	DB_PASSWORD    string `mapstructure:"DB_PASSWORD"`
+	DB_CONNECTIONS string `mapstructure:"DB_CONNECTIONS"`
+	PORT           string `mapstructure:"PORT"`
+	APP_URL        string `mapstructure:"APP_URL"`
+	INFLECTIONS    string `mapstructure:"INFLECTIONS"`
+
+	Connections []DBConnection `mapstructure:"-"`
+}
+
+// DBConnection settings of one named connection. Connections other than main
+// read their variables with the upper-cased name as prefix, e.g. LEGACY_DB_HOST.
+type DBConnection struct {
+	Name     string
+	Driver   string
+	Host     string
+	Port     string
+	Database string
+	User     string
+	Password string
 }
 
 func LoadConfig() (config EnvStructs, err error) {
 	env := os.Getenv("GO_ENV")
 	if env == "production" || env == "development" {
-		return EnvStructs{
-			DB_HOST:     os.Getenv("DB_HOST"),
-			DB_PORT:     os.Getenv("DB_PORT"),
-			DB_DATABASE: os.Getenv("DB_NAME"),
-			DB_USER:     os.Getenv("DB_USER"),
-			DB_PASSWORD: os.Getenv("DB_PASSWORD"),
-
-			APP_URL: os.Getenv("APP_URL"),
-			PORT:    os.Getenv("PORT"),
-		}, nil
+		config = EnvStructs{
+			GO_ENV:         env,
+			DB_DRIVER:      os.Getenv("DB_DRIVER"),
+			DB_HOST:        os.Getenv("DB_HOST"),
+			DB_PORT:        os.Getenv("DB_PORT"),
+			DB_DATABASE:    os.Getenv("DB_NAME"),
+			DB_USER:        os.Getenv("DB_USER"),
+			DB_PASSWORD:    os.Getenv("DB_PASSWORD"),
+			DB_CONNECTIONS: os.Getenv("DB_CONNECTIONS"),
+
+			APP_URL:     os.Getenv("APP_URL"),
+			PORT:        os.Getenv("PORT"),
+			INFLECTIONS: os.Getenv("INFLECTIONS"),
+		}
+		config.Connections, err = loadConnections(config, func(key string) string {
+			if strings.HasSuffix(key, "_DB_DATABASE") {
+				key = strings.TrimSuffix(key, "_DATABASE") + "_NAME"
+			}
+			return os.Getenv(key)
+		})
+		return
 	}
 
 	viper.AddConfigPath(".")
@@ -47,6 +81,15 @@ func LoadConfig() (config EnvStructs, err error) {
 
 	err = viper.Unmarshal(&config)
 
+	if config.DB_DATABASE == "" {
+		err = errors.New("DB_DATABASE is required")
+		return
+	}
+	// a sqlite database is only a file path, DB_DATABASE
+	if config.DB_DRIVER == "sqlite" {
+		config.Connections, err = loadConnections(config, viper.GetString)
+		return
+	}
 	if config.DB_HOST == "" {
 		err = errors.New("DB_HOST is required")
 		return
@@ -55,10 +98,6 @@ func LoadConfig() (config EnvStructs, err error) {
 		err = errors.New("DB_PORT is required")
 		return
 	}
-	if config.DB_DATABASE == "" {
-		err = errors.New("DB_DATABASE is required")
-		return
-	}
 	if config.DB_USER == "" {
 		err = errors.New("DB_USER is required")
 		return
@@ -67,5 +106,73 @@ func LoadConfig() (config EnvStructs, err error) {
 		err = errors.New("DB_PASSWORD is required")
 		return
 	}
+
+	config.Connections, err = loadConnections(config, viper.GetString)
 	return
 }
+
+// Connection looks up a named connection loaded from DB_CONNECTIONS
+func (config EnvStructs) Connection(name string) (DBConnection, bool) {
+	for _, conn := range config.Connections {
+		if conn.Name == name {
+			return conn, true
+		}
+	}
+	return DBConnection{}, false
+}
+
+func loadConnections(config EnvStructs, get func(key string) string) ([]DBConnection, error) {
+	connections := []DBConnection{{
+		Name:     DefaultConnection,
+		Driver:   driverOrDefault(config.DB_DRIVER),
+		Host:     config.DB_HOST,
+		Port:     config.DB_PORT,
+		Database: config.DB_DATABASE,
+		User:     config.DB_USER,
+		Password: config.DB_PASSWORD,
+	}}
+
+	seen := map[string]bool{DefaultConnection: true}
+	for _, name := range strings.Split(config.DB_CONNECTIONS, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		prefix := strings.ToUpper(name) + "_DB_"
+		conn := DBConnection{
+			Name:     name,
+			Driver:   driverOrDefault(get(prefix + "DRIVER")),
+			Host:     get(prefix + "HOST"),
+			Port:     get(prefix + "PORT"),
+			Database: get(prefix + "DATABASE"),
+			User:     get(prefix + "USER"),
+			Password: get(prefix + "PASSWORD"),
+		}
+
+		if conn.Host == "" {
+			return nil, fmt.Errorf("%sHOST is required", prefix)
+		}
+		if conn.Port == "" {
+			return nil, fmt.Errorf("%sPORT is required", prefix)
+		}
+		if conn.Database == "" {
+			return nil, fmt.Errorf("%sDATABASE is required", prefix)
+		}
+		if conn.User == "" {
+			return nil, fmt.Errorf("%sUSER is required", prefix)
+		}
+
+		connections = append(connections, conn)
+	}
+
+	return connections, nil
+}
+
+func driverOrDefault(driver string) string {
+	if driver == "" {
+		return "mysql"
+	}
+	return driver
+}
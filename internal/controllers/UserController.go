@@ -3,7 +3,8 @@ package controllers
 import (
 	controllers "backends/internal/controllers/handler"
 	"backends/internal/models"
-	"backends/internal/storage/query"
+	"backends/internal/repository"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -15,18 +16,20 @@ import (
 
 type UserController struct {
 	controllers.Controller
-	DB *query.DBClient
+	Users repository.UserStore
+	Roles repository.RoleStore
 }
 
-func NewUserController(db *query.DBClient) *UserController {
+func NewUserController(users repository.UserStore, roles repository.RoleStore) *UserController {
 	return &UserController{
-		DB: db,
+		Users: users,
+		Roles: roles,
 	}
 }
 
 func (uc *UserController) GetUsers(c *fiber.Ctx) error {
-	var users []models.User
-	if err := uc.DB.All("users", &users); err != nil {
+	users, err := uc.Users.All()
+	if err != nil {
 		return uc.Error(c, "Internal Server error", fiber.StatusInternalServerError)
 	}
 
@@ -42,9 +45,12 @@ func (uc *UserController) GetUserByID(c *fiber.Ctx) error {
 		return uc.Error(c, "Invalid user ID", fiber.StatusBadRequest)
 	}
 
-	var user models.User
-	if err := uc.DB.Find("users", id, &user); err != nil {
-		return uc.Error(c, "User not found", fiber.StatusNotFound)
+	user, err := uc.Users.Find(id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return uc.Error(c, "User not found", fiber.StatusNotFound)
+		}
+		return uc.Error(c, "Internal Server error", fiber.StatusInternalServerError)
 	}
 
 	return uc.Success(c, fiber.Map{"message": "Data retrieved", "user": user}, fiber.StatusOK)
@@ -57,24 +63,18 @@ func (uc *UserController) CreateUser(c *fiber.Ctx) error {
 		return uc.Error(c, "Invalid request", fiber.StatusBadRequest)
 	}
 
-	cols := []string{"name", "email"}
-	val := []interface{}{user.Name, user.Email}
-
-	if user.RoleId != 0 {
-		if err := uc.DB.Find("roles", user.RoleId, &user.Role); err != nil {
+	if user.RoleId != nil {
+		role, err := uc.Roles.Find(*user.RoleId)
+		if err != nil {
 			return uc.Error(c, "Invalid role id does not exist", fiber.StatusBadRequest)
 		}
-
-		cols = append(cols, "role_id")
-		val = append(val, user.RoleId)
+		user.Role = role
 	}
 
-	lastID, err := uc.DB.Create("users", cols, val)
-	if err != nil {
+	if err := uc.Users.Create(&user); err != nil {
 		return uc.Error(c, "Failed to insert user", fiber.StatusInternalServerError)
 	}
 
-	user.Id = int(lastID)
 	return uc.Success(c, fiber.Map{"message": "User created", "user": user}, fiber.StatusOK)
 }
 
@@ -0,0 +1,92 @@
+package controllers_test
+
+import (
+	"backends/internal/controllers"
+	"backends/internal/models"
+	"backends/internal/repository"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func newUserApp(stores repository.Stores) *fiber.App {
+	userController := controllers.NewUserController(stores.Users, stores.Roles)
+
+	app := fiber.New()
+	app.Get("/users/:id", userController.GetUserByID)
+	app.Post("/users", userController.CreateUser)
+	return app
+}
+
+func request(t *testing.T, app *fiber.App, method, path, body string) *http.Response {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	return resp
+}
+
+func TestGetUserByIDNotFound(t *testing.T) {
+	app := newUserApp(repository.NewMemoryStores())
+
+	resp := request(t, app, http.MethodGet, "/users/42", "")
+	if resp.StatusCode != fiber.StatusNotFound {
+		t.Errorf("status %d, want %d", resp.StatusCode, fiber.StatusNotFound)
+	}
+}
+
+func TestCreateUserInvalidRole(t *testing.T) {
+	stores := repository.NewMemoryStores()
+	app := newUserApp(stores)
+
+	resp := request(t, app, http.MethodPost, "/users", `{"name":"alice","email":"alice@example.com","role_id":7}`)
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("status %d, want %d", resp.StatusCode, fiber.StatusBadRequest)
+	}
+	if users, _ := stores.Users.All(); len(users) != 0 {
+		t.Errorf("user stored despite invalid role: %+v", users)
+	}
+}
+
+func TestCreateUserRoundTrip(t *testing.T) {
+	admin := "admin"
+	stores := repository.Stores{
+		Roles: repository.NewMemoryRoleStore(models.Role{Name: &admin}),
+		Users: repository.NewMemoryUserStore(),
+	}
+	app := newUserApp(stores)
+
+	resp := request(t, app, http.MethodPost, "/users", `{"name":"alice","email":"alice@example.com","role_id":1}`)
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("create: status %d, want %d", resp.StatusCode, fiber.StatusOK)
+	}
+
+	resp = request(t, app, http.MethodGet, "/users/1", "")
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("get: status %d, want %d", resp.StatusCode, fiber.StatusOK)
+	}
+
+	var body struct {
+		Data struct {
+			User models.User `json:"user"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	user := body.Data.User
+	if user.Id != 1 || user.Name == nil || *user.Name != "alice" || user.Email == nil || *user.Email != "alice@example.com" {
+		t.Errorf("got %+v", user)
+	}
+	if user.RoleId == nil || *user.RoleId != 1 {
+		t.Errorf("role_id = %v, want 1", user.RoleId)
+	}
+}
@@ -1,6 +1,7 @@
 package models
 
 type Role struct {
-	Id int `db:"id" gorm:"primaryKey;column:id"`
-	Name string `db:"name" gorm:"column:name"`
-}
\ No newline at end of file
+	Id    int     `db:"id" json:"id" gorm:"primaryKey;column:id"`
+	Name  *string `db:"name" json:"name,omitempty" gorm:"column:name" validate:"omitempty,max=50"`
+	Users []User  `json:"users,omitempty" gorm:"foreignKey:RoleId;references:Id"`
+}
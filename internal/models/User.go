@@ -1,9 +1,9 @@
 package models
 
 type User struct {
-	Id int `db:"id" gorm:"primaryKey;column:id"`
-	Name string `db:"name" gorm:"column:name"`
-	Email string `db:"email" gorm:"column:email"`
-	RoleId int `db:"role_id" gorm:"index;column:role_id"`
-	Role Role `gorm:"foreignKey:RoleId;references:ID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
-}
\ No newline at end of file
+	Id     int     `db:"id" json:"id" gorm:"primaryKey;column:id"`
+	Name   *string `db:"name" json:"name,omitempty" gorm:"column:name" validate:"omitempty,max=100"`
+	Email  *string `db:"email" json:"email,omitempty" gorm:"column:email" validate:"omitempty,max=100"`
+	RoleId *int    `db:"role_id" json:"role_id,omitempty" gorm:"index;column:role_id"`
+	Role   Role    `json:"role,omitempty" gorm:"foreignKey:RoleId;references:Id;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+}
@@ -3,4 +3,4 @@ package models
 var ModelRegistry = []interface{}{
 	new(Role),
 	new(User),
-}
\ No newline at end of file
+}
@@ -0,0 +1,6 @@
+package repository
+
+import "errors"
+
+// ErrNotFound is returned by every store when the record does not exist
+var ErrNotFound = errors.New("record not found")
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"backends/internal/models"
+	"backends/internal/storage/query"
+	"database/sql"
+	"errors"
+	"sort"
+	"sync"
+)
+
+type RoleStore interface {
+	All() ([]models.Role, error)
+	Find(id int) (models.Role, error)
+	Create(role *models.Role) error
+	Update(role *models.Role) error
+	Delete(id int) error
+}
+
+const rolesTable = "roles"
+
+type sqlRoleStore struct {
+	db *query.DBClient
+}
+
+func NewSQLRoleStore(db *query.DBClient) RoleStore {
+	return &sqlRoleStore{db: db}
+}
+
+func (s *sqlRoleStore) All() ([]models.Role, error) {
+	var roles []models.Role
+	if err := s.db.All(rolesTable, &roles); err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+func (s *sqlRoleStore) Find(id int) (models.Role, error) {
+	var role models.Role
+	if err := s.db.Find(rolesTable, id, &role); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return role, ErrNotFound
+		}
+		return role, err
+	}
+	return role, nil
+}
+
+func (s *sqlRoleStore) Create(role *models.Role) error {
+	lastID, err := s.db.Create(rolesTable, []string{"name"}, []interface{}{role.Name})
+	if err != nil {
+		return err
+	}
+
+	role.Id = int(lastID)
+	return nil
+}
+
+func (s *sqlRoleStore) Update(role *models.Role) error {
+	affected, err := s.db.Update(rolesTable, []string{"name"}, []interface{}{role.Name}, role.Id)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		if _, err := s.Find(role.Id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *sqlRoleStore) Delete(id int) error {
+	affected, err := s.db.Delete(rolesTable, id)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+type memoryRoleStore struct {
+	mu     sync.RWMutex
+	roles  map[int]models.Role
+	nextID int
+}
+
+func NewMemoryRoleStore(roles ...models.Role) RoleStore {
+	s := &memoryRoleStore{roles: map[int]models.Role{}}
+	for _, role := range roles {
+		s.Create(&role)
+	}
+	return s
+}
+
+func (s *memoryRoleStore) All() ([]models.Role, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	roles := make([]models.Role, 0, len(s.roles))
+	for _, role := range s.roles {
+		roles = append(roles, role)
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Id < roles[j].Id })
+	return roles, nil
+}
+
+func (s *memoryRoleStore) Find(id int) (models.Role, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	role, ok := s.roles[id]
+	if !ok {
+		return models.Role{}, ErrNotFound
+	}
+	return role, nil
+}
+
+func (s *memoryRoleStore) Create(role *models.Role) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if role.Id == 0 {
+		s.nextID++
+		role.Id = s.nextID
+	} else if role.Id > s.nextID {
+		s.nextID = role.Id
+	}
+	s.roles[role.Id] = *role
+	return nil
+}
+
+func (s *memoryRoleStore) Update(role *models.Role) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.roles[role.Id]; !ok {
+		return ErrNotFound
+	}
+	s.roles[role.Id] = *role
+	return nil
+}
+
+func (s *memoryRoleStore) Delete(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.roles[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.roles, id)
+	return nil
+}
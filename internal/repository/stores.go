@@ -0,0 +1,24 @@
+package repository
+
+import "backends/internal/storage/query"
+
+type Stores struct {
+	Roles RoleStore
+	Users UserStore
+}
+
+// NewSQLStores stores backed by query.DBClient
+func NewSQLStores(db *query.DBClient) Stores {
+	return Stores{
+		Roles: NewSQLRoleStore(db),
+		Users: NewSQLUserStore(db),
+	}
+}
+
+// NewMemoryStores in-memory stores, used for controller tests
+func NewMemoryStores() Stores {
+	return Stores{
+		Roles: NewMemoryRoleStore(),
+		Users: NewMemoryUserStore(),
+	}
+}
@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"backends/internal/models"
+	"backends/internal/storage/query"
+	"database/sql"
+	"errors"
+	"sort"
+	"sync"
+)
+
+type UserStore interface {
+	All() ([]models.User, error)
+	Find(id int) (models.User, error)
+	Create(user *models.User) error
+	Update(user *models.User) error
+	Delete(id int) error
+}
+
+const usersTable = "users"
+
+type sqlUserStore struct {
+	db *query.DBClient
+}
+
+func NewSQLUserStore(db *query.DBClient) UserStore {
+	return &sqlUserStore{db: db}
+}
+
+func (s *sqlUserStore) All() ([]models.User, error) {
+	var users []models.User
+	if err := s.db.All(usersTable, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (s *sqlUserStore) Find(id int) (models.User, error) {
+	var user models.User
+	if err := s.db.Find(usersTable, id, &user); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return user, ErrNotFound
+		}
+		return user, err
+	}
+	return user, nil
+}
+
+func (s *sqlUserStore) Create(user *models.User) error {
+	cols, val := userColumns(user)
+
+	lastID, err := s.db.Create(usersTable, cols, val)
+	if err != nil {
+		return err
+	}
+
+	user.Id = int(lastID)
+	return nil
+}
+
+func (s *sqlUserStore) Update(user *models.User) error {
+	cols, val := userColumns(user)
+
+	affected, err := s.db.Update(usersTable, cols, val, user.Id)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		if _, err := s.Find(user.Id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *sqlUserStore) Delete(id int) error {
+	affected, err := s.db.Delete(usersTable, id)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// userColumns role_id is always written, so Update clears the role when RoleId is nil
+func userColumns(user *models.User) ([]string, []interface{}) {
+	return []string{"name", "email", "role_id"}, []interface{}{user.Name, user.Email, user.RoleId}
+}
+
+type memoryUserStore struct {
+	mu     sync.RWMutex
+	users  map[int]models.User
+	nextID int
+}
+
+func NewMemoryUserStore(users ...models.User) UserStore {
+	s := &memoryUserStore{users: map[int]models.User{}}
+	for _, user := range users {
+		s.Create(&user)
+	}
+	return s
+}
+
+func (s *memoryUserStore) All() ([]models.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	users := make([]models.User, 0, len(s.users))
+	for _, user := range s.users {
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Id < users[j].Id })
+	return users, nil
+}
+
+func (s *memoryUserStore) Find(id int) (models.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return models.User{}, ErrNotFound
+	}
+	return user, nil
+}
+
+func (s *memoryUserStore) Create(user *models.User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if user.Id == 0 {
+		s.nextID++
+		user.Id = s.nextID
+	} else if user.Id > s.nextID {
+		s.nextID = user.Id
+	}
+	s.users[user.Id] = *user
+	return nil
+}
+
+func (s *memoryUserStore) Update(user *models.User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[user.Id]; !ok {
+		return ErrNotFound
+	}
+	s.users[user.Id] = *user
+	return nil
+}
+
+func (s *memoryUserStore) Delete(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.users, id)
+	return nil
+}
@@ -0,0 +1,11 @@
+package routes
+
+import (
+	"backends/internal/repository"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// registerResources registers the routes of every resource scaffolded with make:resource
+func registerResources(api fiber.Router, stores repository.Stores) {
+}
@@ -2,16 +2,13 @@ package routes
 
 import (
 	"backends/internal/controllers"
-	"backends/internal/storage/query"
-	"database/sql"
+	"backends/internal/repository"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-func SetupRoutes(app *fiber.App, db *sql.DB) {
-	dbClient := query.NewDBClient(db)
-	//userRepo := repository.NewUserRepository(db)
-	userController := controllers.NewUserController(dbClient)
+func SetupRoutes(app *fiber.App, stores repository.Stores) {
+	userController := controllers.NewUserController(stores.Users, stores.Roles)
 
 	api := app.Group("/api")
 
@@ -20,6 +17,8 @@ func SetupRoutes(app *fiber.App, db *sql.DB) {
 	api.Post("/users", userController.CreateUser)
 	api.Post("/user/upload", userController.UploadImage)
 
+	registerResources(api, stores)
+
 	app.Use(func(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusNotFound).JSON(
 			fiber.Map{
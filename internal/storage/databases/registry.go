@@ -0,0 +1,125 @@
+package database
+
+import (
+	"backends/config"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kataras/golog"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Registry holds every named connection and closes them together on shutdown
+type Registry struct {
+	mu       sync.RWMutex
+	names    []string
+	conns    map[string]*Database
+	cleanups map[string]func()
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		conns:    map[string]*Database{},
+		cleanups: map[string]func(){},
+	}
+}
+
+// ConnectAll opens every configured connection. Connections that fail are
+// left out of the registry and reported together in the returned error.
+func ConnectAll(connections []config.DBConnection, timeout time.Duration) (*Registry, error) {
+	registry := NewRegistry()
+	var errs []error
+
+	for _, conn := range connections {
+		db, cleanup, err := ConnectDatabase(
+			DBType(conn.Driver),
+			conn.Host, conn.Port, conn.User, conn.Password, conn.Database,
+			timeout,
+		)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("connection %q: %w", conn.Name, err))
+			continue
+		}
+		registry.Add(conn.Name, db, cleanup)
+	}
+
+	return registry, errors.Join(errs...)
+}
+
+func (r *Registry) Add(name string, db *Database, cleanup func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.conns[name]; !exists {
+		r.names = append(r.names, name)
+	} else if previous := r.cleanups[name]; previous != nil {
+		previous()
+	}
+	r.conns[name] = db
+	r.cleanups[name] = cleanup
+}
+
+func (r *Registry) Get(name string) (*Database, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	db, ok := r.conns[name]
+	return db, ok
+}
+
+// Default connection configured by the unprefixed DB_* variables
+func (r *Registry) Default() (*Database, bool) {
+	return r.Get(config.DefaultConnection)
+}
+
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]string(nil), r.names...)
+}
+
+// SQLDB returns the *sql.DB of a named connection, used by query.DBClient
+func (r *Registry) SQLDB(name string) (*sql.DB, error) {
+	db, ok := r.Get(name)
+	if !ok {
+		return nil, fmt.Errorf("database connection %q is not configured", name)
+	}
+	sqlDB := db.GetSQLDB()
+	if sqlDB == nil {
+		return nil, fmt.Errorf("database connection %q is not a SQL database", name)
+	}
+	return sqlDB, nil
+}
+
+func (r *Registry) MongoDB(name string) (*mongo.Client, error) {
+	db, ok := r.Get(name)
+	if !ok {
+		return nil, fmt.Errorf("database connection %q is not configured", name)
+	}
+	client := db.GetMongoDB()
+	if client == nil {
+		return nil, fmt.Errorf("database connection %q is not a MongoDB database", name)
+	}
+	return client, nil
+}
+
+// Close runs the cleanup of every connection, newest first
+func (r *Registry) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := len(r.names) - 1; i >= 0; i-- {
+		name := r.names[i]
+		if cleanup := r.cleanups[name]; cleanup != nil {
+			golog.Infof("Closing database connection %q...", name)
+			cleanup()
+		}
+	}
+	r.names = nil
+	r.conns = map[string]*Database{}
+	r.cleanups = map[string]func(){}
+}
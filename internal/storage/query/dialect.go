@@ -0,0 +1,29 @@
+package query
+
+import (
+	"database/sql"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+)
+
+type Dialect string
+
+const (
+	DialectUnknown  Dialect = ""
+	DialectMySQL    Dialect = "mysql"
+	DialectPostgres Dialect = "postgres"
+)
+
+func detectDialect(db *sql.DB) Dialect {
+	if db == nil {
+		return DialectUnknown
+	}
+	switch db.Driver().(type) {
+	case *mysql.MySQLDriver, mysql.MySQLDriver:
+		return DialectMySQL
+	case *pq.Driver, pq.Driver:
+		return DialectPostgres
+	}
+	return DialectUnknown
+}
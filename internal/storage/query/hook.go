@@ -0,0 +1,67 @@
+package query
+
+import (
+	"sync"
+	"time"
+)
+
+// QueryEvent describes one DBClient operation after it finished
+type QueryEvent struct {
+	Operation string // "find", "all", "create", "update", "delete", "transaction"
+	Table     string
+	Query     string
+	Duration  time.Duration
+	Retries   int // attempts made after the first one
+	Err       error
+}
+
+type Hook interface {
+	AfterQuery(event QueryEvent)
+}
+
+type HookFunc func(event QueryEvent)
+
+func (f HookFunc) AfterQuery(event QueryEvent) {
+	f(event)
+}
+
+func (c *DBClient) AddHook(hook Hook) {
+	c.hooks = append(c.hooks, hook)
+}
+
+func (c *DBClient) fireHooks(event QueryEvent) {
+	for _, hook := range c.hooks {
+		hook.AfterQuery(event)
+	}
+}
+
+// RetryCounter hook that counts retries per operation
+type RetryCounter struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func NewRetryCounter() *RetryCounter {
+	return &RetryCounter{counts: map[string]int{}}
+}
+
+func (r *RetryCounter) AfterQuery(event QueryEvent) {
+	if event.Retries == 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.counts[event.Operation] += event.Retries
+}
+
+// Counts copy of the retry count per operation
+func (r *RetryCounter) Counts() map[string]int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	counts := make(map[string]int, len(r.counts))
+	for op, n := range r.counts {
+		counts[op] = n
+	}
+	return counts
+}
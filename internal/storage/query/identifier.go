@@ -0,0 +1,93 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+var ErrInvalidIdentifier = errors.New("invalid identifier")
+
+// IdentifierError is returned when a table or column name is rejected before
+// it is put into a SQL statement. errors.Is(err, ErrInvalidIdentifier) matches it.
+type IdentifierError struct {
+	Kind   string // "table" or "column"
+	Name   string
+	Reason string
+}
+
+func (e *IdentifierError) Error() string {
+	return fmt.Sprintf("invalid %s name %q: %s", e.Kind, e.Name, e.Reason)
+}
+
+func (e *IdentifierError) Unwrap() error {
+	return ErrInvalidIdentifier
+}
+
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,63}$`)
+
+// ValidateIdentifier accepts plain names of letters, digits and underscores (max 64 chars)
+func ValidateIdentifier(kind, name string) error {
+	if !identifierPattern.MatchString(name) {
+		return &IdentifierError{Kind: kind, Name: name, Reason: "only letters, digits and underscores are allowed"}
+	}
+	return nil
+}
+
+// QuoteIdentifier wraps name in backticks, doubling any backtick inside it
+func QuoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// AllowModels restricts the client to the tables of the given models, e.g.
+// models.ModelRegistry. Table names follow pkg/inflection like the relations
+// of Find, columns come from the db/gorm column tags.
+func (c *DBClient) AllowModels(models ...interface{}) {
+	if c.allowed == nil {
+		c.allowed = map[string]map[string]bool{}
+	}
+
+	for _, model := range models {
+		typ := reflect.TypeOf(model)
+		for typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+
+		table := relatedTable(typ)
+
+		columns := map[string]bool{}
+		for _, column := range getStructFields(reflect.New(typ).Interface()) {
+			columns[column] = true
+		}
+		c.allowed[table] = columns
+	}
+}
+
+func (c *DBClient) validateTable(table string) error {
+	if err := ValidateIdentifier("table", table); err != nil {
+		return err
+	}
+	if c.allowed != nil {
+		if _, ok := c.allowed[table]; !ok {
+			return &IdentifierError{Kind: "table", Name: table, Reason: "table is not registered in the model allowlist"}
+		}
+	}
+	return nil
+}
+
+func (c *DBClient) validateColumns(table string, columns []string) error {
+	if len(columns) == 0 {
+		return &IdentifierError{Kind: "column", Name: "", Reason: "no columns given"}
+	}
+	for _, column := range columns {
+		if err := ValidateIdentifier("column", column); err != nil {
+			return err
+		}
+		if allowed, ok := c.allowed[table]; ok && !allowed[column] {
+			return &IdentifierError{Kind: "column", Name: column, Reason: fmt.Sprintf("column is not a field of table %q", table)}
+		}
+	}
+	return nil
+}
@@ -7,39 +7,148 @@ import (
 )
 
 type DBClient struct {
-	DB *sql.DB
+	DB       *sql.DB
+	tx       *sql.Tx
+	resolver Resolver
+	dialect  Dialect
+	retry    RetryPolicy
+	hooks    []Hook
+	stmts    *stmtCache
+	allowed  map[string]map[string]bool
+}
+
+// Resolver gives access to named SQL connections, implemented by database.Registry
+type Resolver interface {
+	SQLDB(name string) (*sql.DB, error)
+}
+
+// executor is satisfied by both *sql.DB and *sql.Tx
+type executor interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
 }
 
 func NewDBClient(db *sql.DB) *DBClient {
-	return &DBClient{DB: db}
+	client := &DBClient{
+		DB:      db,
+		dialect: detectDialect(db),
+		retry:   DefaultRetryPolicy(),
+	}
+	client.SetStatementCacheSize(DefaultStatementCacheSize)
+	return client
+}
+
+// NewDBClientFor creates a client on the named connection that can switch to
+// the other connections of the resolver with Connection
+func NewDBClientFor(resolver Resolver, name string) (*DBClient, error) {
+	db, err := resolver.SQLDB(name)
+	if err != nil {
+		return nil, err
+	}
+	client := NewDBClient(db)
+	client.resolver = resolver
+	return client, nil
+}
+
+// Connection returns a client on another named connection, keeping the retry policy and hooks
+func (c *DBClient) Connection(name string) (*DBClient, error) {
+	if c.resolver == nil {
+		return nil, fmt.Errorf("no connection resolver configured, cannot select connection %q", name)
+	}
+	client, err := NewDBClientFor(c.resolver, name)
+	if err != nil {
+		return nil, err
+	}
+	client.retry = c.retry
+	client.hooks = append([]Hook(nil), c.hooks...)
+	if c.stmts == nil {
+		client.SetStatementCacheSize(0)
+	} else {
+		client.SetStatementCacheSize(c.stmts.capacity)
+	}
+	return client, nil
+}
+
+func (c *DBClient) Dialect() Dialect {
+	return c.dialect
+}
+
+func (c *DBClient) conn() executor {
+	if c.tx != nil {
+		return c.tx
+	}
+	return c.DB
+}
+
+// Transaction runs fn inside a database transaction. The whole closure is run
+// again when the transaction fails with a transient error, so fn must not have
+// side effects outside the database. Calls on a client that is already in a
+// transaction just run fn in it.
+func (c *DBClient) Transaction(fn func(tx *DBClient) error) error {
+	if c.tx != nil {
+		return fn(c)
+	}
+
+	return c.run("transaction", "", "", func() error {
+		tx, err := c.DB.Begin()
+		if err != nil {
+			return err
+		}
+
+		txClient := *c
+		txClient.tx = tx
+
+		defer func() {
+			if p := recover(); p != nil {
+				tx.Rollback()
+				panic(p)
+			}
+		}()
+
+		if err := fn(&txClient); err != nil {
+			tx.Rollback()
+			return err
+		}
+		return tx.Commit()
+	})
 }
 
 func (c *DBClient) Find(table string, id int, dest interface{}) error {
-	query := fmt.Sprintf("SELECT * FROM `%s` WHERE id = ?", table)
-	row := c.DB.QueryRow(query, id)
+	if err := c.validateTable(table); err != nil {
+		return err
+	}
 
+	query := fmt.Sprintf("SELECT * FROM %s WHERE id = ?", QuoteIdentifier(table))
 	columns := getStructFields(dest)
-	values := make([]interface{}, len(columns))
-	for i := range values {
-		values[i] = new(interface{})
-	}
 
-	if err := row.Scan(values...); err != nil {
+	err := c.run("find", table, query, func() error {
+		values := make([]interface{}, len(columns))
+		for i := range values {
+			values[i] = new(interface{})
+		}
+
+		if err := c.queryRowScan(query, []interface{}{id}, values...); err != nil {
+			return err
+		}
+
+		copyValuesToStruct(values, dest, columns)
+		return nil
+	})
+	if err != nil {
 		return err
 	}
 
-	copyValuesToStruct(values, dest, columns)
 	handleNestedRelations(c, dest)
 	return nil
 }
 
 func (c *DBClient) All(table string, dest interface{}) error {
-	query := fmt.Sprintf("SELECT * FROM `%s`", table)
-	rows, err := c.DB.Query(query)
-	if err != nil {
+	if err := c.validateTable(table); err != nil {
 		return err
 	}
-	defer rows.Close()
+
+	query := fmt.Sprintf("SELECT * FROM %s", QuoteIdentifier(table))
 
 	sliceValue := reflect.ValueOf(dest)
 	if sliceValue.Kind() != reflect.Ptr || sliceValue.Elem().Kind() != reflect.Slice {
@@ -49,21 +158,38 @@ func (c *DBClient) All(table string, dest interface{}) error {
 	structType := sliceValue.Elem().Type().Elem()
 	columns := getStructFields(reflect.New(structType).Interface())
 
-	resultSlice := reflect.MakeSlice(sliceValue.Elem().Type(), 0, 0)
-	for rows.Next() {
-		item := reflect.New(structType).Elem()
-		values := make([]interface{}, len(columns))
-		for i := range values {
-			values[i] = new(interface{})
+	var resultSlice reflect.Value
+	err := c.run("all", table, query, func() error {
+		rows, release, err := c.query(query)
+		if err != nil {
+			return err
 		}
+		defer release()
+		defer rows.Close()
 
-		if err := rows.Scan(values...); err != nil {
-			return err
+		resultSlice = reflect.MakeSlice(sliceValue.Elem().Type(), 0, 0)
+		for rows.Next() {
+			item := reflect.New(structType).Elem()
+			values := make([]interface{}, len(columns))
+			for i := range values {
+				values[i] = new(interface{})
+			}
+
+			if err := rows.Scan(values...); err != nil {
+				return err
+			}
+
+			copyValuesToStruct(values, item.Addr().Interface(), columns)
+			resultSlice = reflect.Append(resultSlice, item)
 		}
+		return rows.Err()
+	})
+	if err != nil {
+		return err
+	}
 
-		copyValuesToStruct(values, item.Addr().Interface(), columns)
-		handleNestedRelations(c, item.Addr().Interface())
-		resultSlice = reflect.Append(resultSlice, item)
+	for i := 0; i < resultSlice.Len(); i++ {
+		handleNestedRelations(c, resultSlice.Index(i).Addr().Interface())
 	}
 
 	sliceValue.Elem().Set(resultSlice)
@@ -71,29 +197,65 @@ func (c *DBClient) All(table string, dest interface{}) error {
 }
 
 func (c *DBClient) Create(table string, columns []string, values []interface{}) (int64, error) {
-	query := fmt.Sprintf("INSERT INTO `%s` (%s) VALUES %s", table, joinColumns(columns), generatePlaceholders(len(columns)))
-	result, err := c.DB.Exec(query, values...)
-	if err != nil {
+	if err := c.validateTable(table); err != nil {
+		return 0, err
+	}
+	if err := c.validateColumns(table, columns); err != nil {
 		return 0, err
 	}
-	return result.LastInsertId()
+
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s", QuoteIdentifier(table), joinColumns(columns), generatePlaceholders(len(columns)))
+
+	var lastID int64
+	err := c.run("create", table, query, func() error {
+		result, err := c.exec(query, values...)
+		if err != nil {
+			return err
+		}
+		lastID, err = result.LastInsertId()
+		return err
+	})
+	return lastID, err
 }
 
 func (c *DBClient) Update(table string, columns []string, values []interface{}, id int) (int64, error) {
-	query := fmt.Sprintf("UPDATE `%s` SET %s WHERE id = ?", table, generateUpdateSetQuery(columns))
-	values = append(values, id)
-	result, err := c.DB.Exec(query, values...)
-	if err != nil {
+	if err := c.validateTable(table); err != nil {
 		return 0, err
 	}
-	return result.RowsAffected()
+	if err := c.validateColumns(table, columns); err != nil {
+		return 0, err
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE id = ?", QuoteIdentifier(table), generateUpdateSetQuery(columns))
+	values = append(values, id)
+
+	var affected int64
+	err := c.run("update", table, query, func() error {
+		result, err := c.exec(query, values...)
+		if err != nil {
+			return err
+		}
+		affected, err = result.RowsAffected()
+		return err
+	})
+	return affected, err
 }
 
 func (c *DBClient) Delete(table string, id int) (int64, error) {
-	query := fmt.Sprintf("DELETE FROM `%s` WHERE id = ?", table)
-	result, err := c.DB.Exec(query, id)
-	if err != nil {
+	if err := c.validateTable(table); err != nil {
 		return 0, err
 	}
-	return result.RowsAffected()
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE id = ?", QuoteIdentifier(table))
+
+	var affected int64
+	err := c.run("delete", table, query, func() error {
+		result, err := c.exec(query, id)
+		if err != nil {
+			return err
+		}
+		affected, err = result.RowsAffected()
+		return err
+	})
+	return affected, err
 }
@@ -0,0 +1,135 @@
+package query
+
+import (
+	"database/sql/driver"
+	"errors"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+)
+
+// RetryPolicy controls how DBClient retries statements and transactions that
+// failed with a transient error (deadlock, lock wait timeout, serialization
+// failure). Create is only retried when the connection failed before the
+// INSERT was sent.
+type RetryPolicy struct {
+	MaxAttempts    int // total attempts including the first one, 1 disables retrying
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+
+	// IsRetryable overrides the default IsTransient check
+	IsRetryable func(dialect Dialect, err error) bool
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 50 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Multiplier:     2,
+	}
+}
+
+func NoRetry() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 1}
+}
+
+// IsTransient reports whether err is a deadlock or lock timeout for the dialect.
+// The server has rolled back the failed statement for every error listed here,
+// so running it again cannot apply it twice.
+func IsTransient(dialect Dialect, err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if dialect == DialectMySQL || dialect == DialectUnknown {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) {
+			switch mysqlErr.Number {
+			case 1213, // ER_LOCK_DEADLOCK
+				1205: // ER_LOCK_WAIT_TIMEOUT
+				return true
+			}
+		}
+	}
+
+	if dialect == DialectPostgres || dialect == DialectUnknown {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) {
+			switch pqErr.Code {
+			case "40001", // serialization_failure
+				"40P01", // deadlock_detected
+				"55P03": // lock_not_available
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// nonIdempotent operations that must not run twice. An INSERT that failed
+// with a timeout or a reset connection may have been committed anyway.
+var nonIdempotent = map[string]bool{"create": true}
+
+func (p RetryPolicy) retryable(operation string, dialect Dialect, err error) bool {
+	if nonIdempotent[operation] {
+		// only retried when the statement never reached the server
+		return errors.Is(err, driver.ErrBadConn)
+	}
+	if p.IsRetryable != nil {
+		return p.IsRetryable(dialect, err)
+	}
+	return IsTransient(dialect, err)
+}
+
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	delay := p.InitialBackoff
+	for i := 1; i < retry; i++ {
+		if p.Multiplier > 1 {
+			delay = time.Duration(float64(delay) * p.Multiplier)
+		}
+		if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+			return p.MaxBackoff
+		}
+	}
+	return delay
+}
+
+func (c *DBClient) SetRetryPolicy(policy RetryPolicy) {
+	c.retry = policy
+}
+
+func (c *DBClient) RetryPolicy() RetryPolicy {
+	return c.retry
+}
+
+// run executes fn with the retry policy and reports the result to the hooks.
+// Inside a transaction a single statement is never retried, a deadlock aborts
+// the whole transaction so only the Transaction closure can be run again.
+func (c *DBClient) run(operation, table, query string, fn func() error) error {
+	start := time.Now()
+	retries := 0
+
+	var err error
+	for {
+		err = fn()
+		if err == nil || c.tx != nil || retries+1 >= c.retry.MaxAttempts || !c.retry.retryable(operation, c.dialect, err) {
+			break
+		}
+		retries++
+		time.Sleep(c.retry.backoff(retries))
+	}
+
+	c.fireHooks(QueryEvent{
+		Operation: operation,
+		Table:     table,
+		Query:     query,
+		Duration:  time.Since(start),
+		Retries:   retries,
+		Err:       err,
+	})
+	return err
+}
@@ -0,0 +1,46 @@
+package query
+
+import (
+	"database/sql/driver"
+	"errors"
+	"testing"
+)
+
+func TestCreateIsNotRetried(t *testing.T) {
+	client := newTestClient(t,
+		"CREATE TABLE items (id INTEGER PRIMARY KEY, name VARCHAR(100) NOT NULL)",
+		"INSERT INTO items (id, name) VALUES (1, 'root')",
+	)
+	// a policy that retries every error, as one treating timeouts as transient would
+	client.SetRetryPolicy(RetryPolicy{
+		MaxAttempts: 3,
+		IsRetryable: func(Dialect, error) bool { return true },
+	})
+	counter := NewRetryCounter()
+	client.AddHook(counter)
+
+	if _, err := client.Create("items", []string{"name"}, []interface{}{nil}); err == nil {
+		t.Fatal("insert of a NULL name succeeded")
+	}
+	if _, err := client.Update("items", []string{"name"}, []interface{}{nil}, 1); err == nil {
+		t.Fatal("update to a NULL name succeeded")
+	}
+
+	counts := counter.Counts()
+	if counts["create"] != 0 {
+		t.Errorf("create retried %d times, want 0", counts["create"])
+	}
+	if counts["update"] != 2 {
+		t.Errorf("update retried %d times, want 2", counts["update"])
+	}
+}
+
+func TestCreateRetriesUnsentStatement(t *testing.T) {
+	policy := DefaultRetryPolicy()
+	if !policy.retryable("create", DialectMySQL, driver.ErrBadConn) {
+		t.Error("create not retried on a connection that failed before sending")
+	}
+	if policy.retryable("create", DialectMySQL, errors.New("i/o timeout")) {
+		t.Error("create retried after a timeout")
+	}
+}
@@ -0,0 +1,238 @@
+package query
+
+import (
+	"container/list"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"sync"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+const DefaultStatementCacheSize = 100
+
+// stmtCache keeps prepared statements keyed by their SQL, evicting the least
+// recently used one when full. A statement is only closed once nobody uses it.
+type stmtCache struct {
+	mu       sync.Mutex
+	db       *sql.DB
+	capacity int
+	order    *list.List
+	entries  map[string]*list.Element
+}
+
+type cachedStmt struct {
+	query   string
+	stmt    *sql.Stmt
+	refs    int
+	evicted bool
+}
+
+func newStmtCache(db *sql.DB, capacity int) *stmtCache {
+	return &stmtCache{
+		db:       db,
+		capacity: capacity,
+		order:    list.New(),
+		entries:  map[string]*list.Element{},
+	}
+}
+
+// acquire returns the prepared statement for query, preparing it on a miss.
+// The returned release func must be called once the statement is no longer used.
+func (c *stmtCache) acquire(query string) (*sql.Stmt, func(), error) {
+	c.mu.Lock()
+	if el, ok := c.entries[query]; ok {
+		c.order.MoveToFront(el)
+		entry := el.Value.(*cachedStmt)
+		entry.refs++
+		c.mu.Unlock()
+		return entry.stmt, c.releaseFunc(entry), nil
+	}
+	c.mu.Unlock()
+
+	stmt, err := c.db.Prepare(query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// another goroutine may have prepared the same query meanwhile
+	if el, ok := c.entries[query]; ok {
+		stmt.Close()
+		c.order.MoveToFront(el)
+		entry := el.Value.(*cachedStmt)
+		entry.refs++
+		return entry.stmt, c.releaseFunc(entry), nil
+	}
+
+	entry := &cachedStmt{query: query, stmt: stmt, refs: 1}
+	c.entries[query] = c.order.PushFront(entry)
+	for c.order.Len() > c.capacity {
+		c.removeLocked(c.order.Back())
+	}
+	return stmt, c.releaseFunc(entry), nil
+}
+
+func (c *stmtCache) releaseFunc(entry *cachedStmt) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			entry.refs--
+			if entry.evicted && entry.refs == 0 {
+				entry.stmt.Close()
+			}
+		})
+	}
+}
+
+func (c *stmtCache) invalidate(query string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[query]; ok {
+		c.removeLocked(el)
+	}
+}
+
+func (c *stmtCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for c.order.Len() > 0 {
+		c.removeLocked(c.order.Back())
+	}
+}
+
+func (c *stmtCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *stmtCache) removeLocked(el *list.Element) {
+	entry := c.order.Remove(el).(*cachedStmt)
+	delete(c.entries, entry.query)
+	entry.evicted = true
+	if entry.refs == 0 {
+		entry.stmt.Close()
+	}
+}
+
+// isConnectionError reports errors after which a cached statement should be prepared again
+func isConnectionError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, mysql.ErrInvalidConn) || errors.Is(err, sql.ErrConnDone) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	return err.Error() == "sql: statement is closed"
+}
+
+// SetStatementCacheSize changes how many prepared statements are kept, 0 disables the cache
+func (c *DBClient) SetStatementCacheSize(size int) {
+	if c.stmts != nil {
+		c.stmts.clear()
+		c.stmts = nil
+	}
+	if size > 0 && c.DB != nil {
+		c.stmts = newStmtCache(c.DB, size)
+	}
+}
+
+// CloseStatements closes every cached prepared statement
+func (c *DBClient) CloseStatements() {
+	if c.stmts != nil {
+		c.stmts.clear()
+	}
+}
+
+// prepared returns the statement to run query with, bound to the current
+// transaction when there is one. ok is false when the cache is disabled.
+func (c *DBClient) prepared(query string) (stmt *sql.Stmt, release func(), ok bool, err error) {
+	if c.stmts == nil {
+		return nil, nil, false, nil
+	}
+
+	stmt, release, err = c.stmts.acquire(query)
+	if err != nil {
+		return nil, nil, true, err
+	}
+	if c.tx != nil {
+		txStmt, releaseParent := c.tx.Stmt(stmt), release
+		return txStmt, func() {
+			txStmt.Close()
+			releaseParent()
+		}, true, nil
+	}
+	return stmt, release, true, nil
+}
+
+func (c *DBClient) evictOnConnectionError(query string, err error) {
+	if c.stmts != nil && isConnectionError(err) {
+		c.stmts.invalidate(query)
+	}
+}
+
+func (c *DBClient) exec(query string, args ...interface{}) (sql.Result, error) {
+	stmt, release, ok, err := c.prepared(query)
+	if !ok {
+		return c.conn().Exec(query, args...)
+	}
+	if err != nil {
+		c.evictOnConnectionError(query, err)
+		return nil, err
+	}
+	defer release()
+
+	result, err := stmt.Exec(args...)
+	c.evictOnConnectionError(query, err)
+	return result, err
+}
+
+// query the returned release func must be called after rows are closed
+func (c *DBClient) query(query string, args ...interface{}) (*sql.Rows, func(), error) {
+	stmt, release, ok, err := c.prepared(query)
+	if !ok {
+		rows, err := c.conn().Query(query, args...)
+		return rows, func() {}, err
+	}
+	if err != nil {
+		c.evictOnConnectionError(query, err)
+		return nil, nil, err
+	}
+
+	rows, err := stmt.Query(args...)
+	if err != nil {
+		release()
+		c.evictOnConnectionError(query, err)
+		return nil, nil, err
+	}
+	return rows, release, nil
+}
+
+func (c *DBClient) queryRowScan(query string, args []interface{}, dest ...interface{}) error {
+	stmt, release, ok, err := c.prepared(query)
+	if !ok {
+		return c.conn().QueryRow(query, args...).Scan(dest...)
+	}
+	if err != nil {
+		c.evictOnConnectionError(query, err)
+		return err
+	}
+	defer release()
+
+	err = stmt.QueryRow(args...).Scan(dest...)
+	c.evictOnConnectionError(query, err)
+	return err
+}
@@ -0,0 +1,118 @@
+package query
+
+import "testing"
+
+func TestStmtCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	client := newTestClient(t)
+	cache := newStmtCache(client.DB, 2)
+
+	use := func(query string) {
+		t.Helper()
+		_, release, err := cache.acquire(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		release()
+	}
+
+	use("SELECT 1")
+	use("SELECT 2")
+	use("SELECT 1") // SELECT 2 is now the least recently used
+	use("SELECT 3")
+
+	if cache.len() != 2 {
+		t.Fatalf("cache holds %d statements, want 2", cache.len())
+	}
+	for query, cached := range map[string]bool{"SELECT 1": true, "SELECT 2": false, "SELECT 3": true} {
+		if _, ok := cache.entries[query]; ok != cached {
+			t.Errorf("%s cached = %v, want %v", query, ok, cached)
+		}
+	}
+}
+
+func TestStmtCacheClosesEvictedStatementOnRelease(t *testing.T) {
+	client := newTestClient(t)
+	cache := newStmtCache(client.DB, 1)
+
+	stmt, release, err := cache.acquire("SELECT 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// evicts SELECT 1 while it is still in use
+	_, releaseOther, err := cache.acquire("SELECT 2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	releaseOther()
+
+	var n int
+	if err := stmt.QueryRow().Scan(&n); err != nil {
+		t.Fatalf("evicted statement closed while in use: %v", err)
+	}
+
+	release()
+	release() // a second call must not drop the count of another user
+	if err := stmt.QueryRow().Scan(&n); err == nil {
+		t.Fatal("evicted statement still open after its last release")
+	}
+}
+
+func TestStmtCacheInvalidate(t *testing.T) {
+	client := newTestClient(t)
+	cache := newStmtCache(client.DB, 2)
+
+	first, release, err := cache.acquire("SELECT 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+	cache.invalidate("SELECT 1")
+
+	second, release, err := cache.acquire("SELECT 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+	if first == second {
+		t.Error("invalidated statement was reused")
+	}
+}
+
+func benchmarkFind(b *testing.B, cacheSize int) {
+	client := newTestClient(b,
+		"CREATE TABLE items (id INTEGER PRIMARY KEY, name VARCHAR(100) NULL, parent_id INTEGER NULL, score DOUBLE NULL)",
+		"INSERT INTO items (id, name, parent_id, score) VALUES (1, 'root', NULL, 1.5)",
+	)
+	client.SetStatementCacheSize(cacheSize)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var row nullableRow
+		if err := client.Find("items", 1, &row); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFind(b *testing.B) {
+	b.Run("cached", func(b *testing.B) { benchmarkFind(b, DefaultStatementCacheSize) })
+	b.Run("uncached", func(b *testing.B) { benchmarkFind(b, 0) })
+}
+
+func benchmarkCreate(b *testing.B, cacheSize int) {
+	client := newTestClient(b, "CREATE TABLE items (id INTEGER PRIMARY KEY, name VARCHAR(100) NULL)")
+	client.SetStatementCacheSize(cacheSize)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := client.Create("items", []string{"name"}, []interface{}{"item"}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCreate(b *testing.B) {
+	b.Run("cached", func(b *testing.B) { benchmarkCreate(b, DefaultStatementCacheSize) })
+	b.Run("uncached", func(b *testing.B) { benchmarkCreate(b, 0) })
+}
@@ -48,51 +48,98 @@ func copyValuesToStruct(values []interface{}, dest interface{}, columns []string
 					// Debugging log
 					// fmt.Printf("Setting field: %s, Value: %v, Type: %T\n", field.Name, *val, *val)
 
-					switch fieldValue.Kind() {
-					case reflect.String:
-						switch v := (*val).(type) {
-						case []uint8:
-							fieldValue.SetString(string(v))
-						case string:
-							fieldValue.SetString(v)
-						case nil:
-							fieldValue.SetString("")
-						default:
-							fieldValue.SetString(fmt.Sprintf("%v", v))
-						}
-
-					case reflect.Int, reflect.Int64:
-						switch v := (*val).(type) {
-						case int:
-							fieldValue.SetInt(int64(v))
-						case int64:
-							fieldValue.SetInt(v)
-						case float64:
-							fieldValue.SetInt(int64(v))
-						case []uint8:
-							intVal, err := strconv.Atoi(string(v))
-							if err == nil {
-								fieldValue.SetInt(int64(intVal))
-							}
-						}
-					case reflect.Float64:
-						if floatVal, ok := (*val).(float64); ok {
-							fieldValue.SetFloat(floatVal)
-						} else if byteSlice, ok := (*val).([]uint8); ok {
-							floatVal, err := strconv.ParseFloat(string(byteSlice), 64)
-							if err == nil {
-								fieldValue.SetFloat(floatVal)
-							}
-						}
-					case reflect.Struct:
-						structField := fieldValue.Addr().Interface()
-						copyValuesToStruct([]interface{}{*val}, structField, []string{col})
-					default:
-						fieldValue.Set(reflect.ValueOf(*val))
-					}
+					setFieldValue(fieldValue, *val, col)
 				}
 				break
 			}
 		}
 	}
 }
+
+// setFieldValue stores a scanned column value in a field, NULL leaves a pointer field nil
+func setFieldValue(fieldValue reflect.Value, val interface{}, col string) {
+	if fieldValue.Kind() == reflect.Ptr {
+		if val == nil {
+			fieldValue.Set(reflect.Zero(fieldValue.Type()))
+			return
+		}
+		ptr := reflect.New(fieldValue.Type().Elem())
+		setFieldValue(ptr.Elem(), val, col)
+		fieldValue.Set(ptr)
+		return
+	}
+
+	switch fieldValue.Kind() {
+	case reflect.String:
+		switch v := val.(type) {
+		case []uint8:
+			fieldValue.SetString(string(v))
+		case string:
+			fieldValue.SetString(v)
+		case nil:
+			fieldValue.SetString("")
+		default:
+			fieldValue.SetString(fmt.Sprintf("%v", v))
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		switch v := val.(type) {
+		case int:
+			fieldValue.SetInt(int64(v))
+		case int64:
+			fieldValue.SetInt(v)
+		case float64:
+			fieldValue.SetInt(int64(v))
+		case []uint8:
+			intVal, err := strconv.ParseInt(string(v), 10, 64)
+			if err == nil {
+				fieldValue.SetInt(intVal)
+			}
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		switch v := val.(type) {
+		case int64:
+			fieldValue.SetUint(uint64(v))
+		case uint64:
+			fieldValue.SetUint(v)
+		case []uint8:
+			uintVal, err := strconv.ParseUint(string(v), 10, 64)
+			if err == nil {
+				fieldValue.SetUint(uintVal)
+			}
+		}
+	case reflect.Bool:
+		switch v := val.(type) {
+		case bool:
+			fieldValue.SetBool(v)
+		case int64:
+			fieldValue.SetBool(v != 0)
+		case []uint8:
+			fieldValue.SetBool(len(v) > 0 && v[0] != 0 && v[0] != '0')
+		}
+	case reflect.Float32, reflect.Float64:
+		if floatVal, ok := val.(float64); ok {
+			fieldValue.SetFloat(floatVal)
+		} else if byteSlice, ok := val.([]uint8); ok {
+			floatVal, err := strconv.ParseFloat(string(byteSlice), 64)
+			if err == nil {
+				fieldValue.SetFloat(floatVal)
+			}
+		}
+	case reflect.Struct:
+		if val != nil && reflect.TypeOf(val).AssignableTo(fieldValue.Type()) {
+			fieldValue.Set(reflect.ValueOf(val))
+			return
+		}
+		structField := fieldValue.Addr().Interface()
+		copyValuesToStruct([]interface{}{&val}, structField, []string{col})
+	default:
+		if val == nil {
+			fieldValue.Set(reflect.Zero(fieldValue.Type()))
+			return
+		}
+		if rv := reflect.ValueOf(val); rv.Type().ConvertibleTo(fieldValue.Type()) {
+			fieldValue.Set(rv.Convert(fieldValue.Type()))
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package query
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/glebarez/go-sqlite"
+)
+
+// newTestClient a client on a fresh SQLite database holding the given statements
+func newTestClient(t testing.TB, statements ...string) *DBClient {
+	t.Helper()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	for _, statement := range statements {
+		if _, err := db.Exec(statement); err != nil {
+			t.Fatalf("%s: %v", statement, err)
+		}
+	}
+	return NewDBClient(db)
+}
+
+type nullableRow struct {
+	Id       int      `db:"id"`
+	Name     *string  `db:"name"`
+	ParentId *int     `db:"parent_id"`
+	Score    *float64 `db:"score"`
+}
+
+func TestFindNullableColumns(t *testing.T) {
+	client := newTestClient(t,
+		"CREATE TABLE items (id INTEGER PRIMARY KEY, name VARCHAR(100) NULL, parent_id INTEGER NULL, score DOUBLE NULL)",
+		"INSERT INTO items (id, name, parent_id, score) VALUES (1, 'root', NULL, NULL)",
+		"INSERT INTO items (id, name, parent_id, score) VALUES (2, NULL, 1, 2.5)",
+	)
+
+	var root nullableRow
+	if err := client.Find("items", 1, &root); err != nil {
+		t.Fatal(err)
+	}
+	if root.Name == nil || *root.Name != "root" {
+		t.Errorf("name = %v, want root", root.Name)
+	}
+	if root.ParentId != nil || root.Score != nil {
+		t.Errorf("NULL columns scanned as parent_id %v, score %v, want nil", root.ParentId, root.Score)
+	}
+
+	child := nullableRow{Name: new(string)}
+	if err := client.Find("items", 2, &child); err != nil {
+		t.Fatal(err)
+	}
+	if child.Name != nil {
+		t.Errorf("name = %q, want nil", *child.Name)
+	}
+	if child.ParentId == nil || *child.ParentId != 1 {
+		t.Errorf("parent_id = %v, want 1", child.ParentId)
+	}
+	if child.Score == nil || *child.Score != 2.5 {
+		t.Errorf("score = %v, want 2.5", child.Score)
+	}
+
+	var all []nullableRow
+	if err := client.All("items", &all); err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 || all[1].ParentId == nil || all[0].ParentId != nil {
+		t.Errorf("All scanned %+v", all)
+	}
+}
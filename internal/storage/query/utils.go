@@ -1,9 +1,12 @@
 package query
 
 import (
+	"backends/pkg/inflection"
 	"fmt"
 	"reflect"
 	"strings"
+
+	"gorm.io/gorm/schema"
 )
 
 func extractForeignKey(v reflect.Value, field reflect.StructField) (string, int, bool) {
@@ -14,9 +17,18 @@ func extractForeignKey(v reflect.Value, field reflect.StructField) (string, int,
 			if strings.HasPrefix(part, "foreignKey:") {
 				relKey := strings.TrimPrefix(part, "foreignKey:")
 				relIDField := v.FieldByName(relKey)
-				if relIDField.IsValid() && relIDField.Kind() == reflect.Int {
-					relTable := strings.ToLower(field.Type.Name()) + "s"
+				if relIDField.Kind() == reflect.Ptr {
+					if relIDField.IsNil() {
+						return "", 0, false
+					}
+					relIDField = relIDField.Elem()
+				}
+				relTable := relatedTable(field.Type)
+				switch relIDField.Kind() {
+				case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 					return relTable, int(relIDField.Int()), true
+				case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+					return relTable, int(relIDField.Uint()), true
 				}
 			}
 		}
@@ -24,8 +36,23 @@ func extractForeignKey(v reflect.Value, field reflect.StructField) (string, int,
 	return "", 0, false
 }
 
+// relatedTable the table of a relation field's model, its TableName when it has one
+func relatedTable(typ reflect.Type) string {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if tabler, ok := reflect.New(typ).Interface().(schema.Tabler); ok {
+		return tabler.TableName()
+	}
+	return inflection.TableName(typ.Name())
+}
+
 func joinColumns(columns []string) string {
-	return fmt.Sprintf("`%s`", stringJoin(columns, "`, `"))
+	quoted := make([]string, len(columns))
+	for i, col := range columns {
+		quoted[i] = QuoteIdentifier(col)
+	}
+	return stringJoin(quoted, ", ")
 }
 
 func generatePlaceholders(count int) string {
@@ -39,7 +66,7 @@ func generatePlaceholders(count int) string {
 func generateUpdateSetQuery(columns []string) string {
 	var parts []string
 	for _, col := range columns {
-		parts = append(parts, fmt.Sprintf("`%s` = ?", col))
+		parts = append(parts, fmt.Sprintf("%s = ?", QuoteIdentifier(col)))
 	}
 	return stringJoin(parts, ", ")
 }
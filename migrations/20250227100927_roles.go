@@ -7,10 +7,47 @@ type Roles struct {
 	Name string `gorm:"type:varchar(50);unique"`
 }
 
+// usersRole the users.role_id foreign key, created here because roles is
+// created after users
+type usersRole struct {
+	ID     int32  `gorm:"primaryKey"`
+	RoleID int32  `gorm:"index"`
+	Role   *Roles `gorm:"foreignKey:RoleID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+}
+
+func (usersRole) TableName() string {
+	return "users"
+}
+
 func Up20250227100927Roles(db *gorm.DB) error {
-	return db.AutoMigrate(&Roles{})
+	if err := db.AutoMigrate(&Roles{}); err != nil {
+		return err
+	}
+	if !db.Migrator().HasTable(&usersRole{}) || db.Migrator().HasConstraint(&usersRole{}, "Role") {
+		return nil
+	}
+	if err := db.Migrator().CreateConstraint(&usersRole{}, "Role"); err != nil {
+		return err
+	}
+	return keepRoleIndex(db)
 }
 
-func DownUp20250227100927Roles(db *gorm.DB) error {
+func Down20250227100927Roles(db *gorm.DB) error {
+	if db.Migrator().HasTable(&usersRole{}) && db.Migrator().HasConstraint(&usersRole{}, "Role") {
+		if err := db.Migrator().DropConstraint(&usersRole{}, "Role"); err != nil {
+			return err
+		}
+		if err := keepRoleIndex(db); err != nil {
+			return err
+		}
+	}
 	return db.Migrator().DropTable("roles")
 }
+
+// keepRoleIndex SQLite rebuilds the table to add or drop a constraint and loses its indexes
+func keepRoleIndex(db *gorm.DB) error {
+	if db.Migrator().HasIndex(&usersRole{}, "RoleID") {
+		return nil
+	}
+	return db.Migrator().CreateIndex(&usersRole{}, "RoleID")
+}
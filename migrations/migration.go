@@ -0,0 +1,24 @@
+package migrations
+
+import (
+	"regexp"
+
+	"gorm.io/gorm"
+)
+
+type Migration struct {
+	Name string
+	Up   func(*gorm.DB) error
+	Down func(*gorm.DB) error
+}
+
+var versionRegex = regexp.MustCompile(`^(?:Up|Down)?(\d{14})`)
+
+// Version timestamp prefix of the migration name, e.g. 20250226160158
+func (m Migration) Version() string {
+	matches := versionRegex.FindStringSubmatch(m.Name)
+	if len(matches) == 2 {
+		return matches[1]
+	}
+	return ""
+}
@@ -1,8 +1,6 @@
 package migrations
 
-import "gorm.io/gorm"
-
-var MigrationRegistry = map[string]func(*gorm.DB) error{
-	"Up20250226160158Users": Up20250226160158Users,
-	"Up20250227100927Roles": Up20250227100927Roles,
+var MigrationRegistry = []Migration{
+	{Name: "Up20250226160158Users", Up: Up20250226160158Users, Down: Down20250226160158Users},
+	{Name: "Up20250227100927Roles", Up: Up20250227100927Roles, Down: Down20250227100927Roles},
 }
@@ -0,0 +1,267 @@
+// Package inflection converts between the singular and plural forms of English
+// words and between model and table names. Only the last word of a compound
+// name is inflected, order_items <-> OrderItem, and irregular and uncountable
+// words are checked before the regular rules of github.com/jinzhu/inflection.
+package inflection
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/jinzhu/inflection"
+)
+
+var (
+	mu sync.RWMutex
+
+	// irregular singular -> plural, checked against whole words only
+	irregular = map[string]string{
+		"person":    "people",
+		"man":       "men",
+		"woman":     "women",
+		"human":     "humans",
+		"child":     "children",
+		"foot":      "feet",
+		"tooth":     "teeth",
+		"goose":     "geese",
+		"mouse":     "mice",
+		"criterion": "criteria",
+		"cactus":    "cacti",
+		"radius":    "radii",
+		"sex":       "sexes",
+		"move":      "moves",
+	}
+	singulars = map[string]string{}
+
+	uncountable = map[string]bool{
+		"equipment":   true,
+		"information": true,
+		"rice":        true,
+		"money":       true,
+		"species":     true,
+		"series":      true,
+		"fish":        true,
+		"sheep":       true,
+		"jeans":       true,
+		"police":      true,
+		"news":        true,
+		"metadata":    true,
+		"feedback":    true,
+		"staff":       true,
+	}
+)
+
+func init() {
+	for singular, plural := range irregular {
+		singulars[plural] = singular
+	}
+	register()
+}
+
+// register replaces the word lists of github.com/jinzhu/inflection, which
+// GORM's NamingStrategy uses, with ours, so tables GORM names itself agree
+// with TableName. jinzhu matches irregular words as suffixes in list order,
+// longer words go first so human is not read as hu-man. Callers hold mu or
+// run in init.
+func register() {
+	var singularWords []string
+	for singular := range irregular {
+		singularWords = append(singularWords, singular)
+	}
+	sort.Slice(singularWords, func(i, j int) bool {
+		if len(singularWords[i]) != len(singularWords[j]) {
+			return len(singularWords[i]) > len(singularWords[j])
+		}
+		return singularWords[i] < singularWords[j]
+	})
+	inflection.SetIrregular(nil)
+	for _, singular := range singularWords {
+		inflection.AddIrregular(singular, irregular[singular])
+	}
+
+	var words []string
+	for word := range uncountable {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+	inflection.SetUncountable(words)
+}
+
+// AddIrregular registers an irregular pair, replacing any earlier pair of either word
+func AddIrregular(singular, plural string) {
+	singular, plural = strings.ToLower(singular), strings.ToLower(plural)
+	mu.Lock()
+	defer mu.Unlock()
+	delete(uncountable, singular)
+	delete(uncountable, plural)
+	irregular[singular] = plural
+	singulars[plural] = singular
+	register()
+}
+
+// AddUncountable registers words that are the same in singular and plural
+func AddUncountable(words ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, word := range words {
+		uncountable[strings.ToLower(word)] = true
+	}
+	register()
+}
+
+// Configure applies a comma separated list of overrides, singular:plural for an
+// irregular pair and a bare word for an uncountable one, e.g. "cafe:cafes,staff"
+func Configure(spec string) error {
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		singular, plural, isPair := strings.Cut(entry, ":")
+		singular, plural = strings.TrimSpace(singular), strings.TrimSpace(plural)
+		if !isWord(singular) || (isPair && !isWord(plural)) {
+			return fmt.Errorf("invalid inflection %q, expected singular:plural or a single word", entry)
+		}
+		if isPair {
+			AddIrregular(singular, plural)
+		} else {
+			AddUncountable(singular)
+		}
+	}
+	return nil
+}
+
+// Plural the plural of a word or of the last word of a name, category -> categories
+func Plural(name string) string {
+	return inflectLast(name, func(word string) string {
+		if p, ok := irregular[word]; ok {
+			return p
+		}
+		if _, ok := singulars[word]; ok {
+			return word
+		}
+		return inflection.Plural(word)
+	})
+}
+
+// Singular the singular of a word or of the last word of a name, addresses -> address
+func Singular(name string) string {
+	return inflectLast(name, func(word string) string {
+		if s, ok := singulars[word]; ok {
+			return s
+		}
+		if _, ok := irregular[word]; ok {
+			return word
+		}
+		return inflection.Singular(word)
+	})
+}
+
+// TableName the table of a model, OrderItem -> order_items
+func TableName(model string) string {
+	return Plural(Underscore(model))
+}
+
+// ModelName the model struct name of a table, order_items -> OrderItem
+func ModelName(table string) string {
+	return Camelize(Singular(table))
+}
+
+// Underscore the snake_case form of a CamelCase name, OrderItem -> order_item, UserID -> user_id
+func Underscore(name string) string {
+	var b strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			previousLower := i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]))
+			endOfAcronym := i > 0 && unicode.IsUpper(runes[i-1]) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if previousLower || endOfAcronym {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Camelize the CamelCase form of a snake_case name, order_item -> OrderItem
+func Camelize(name string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(name, "_") {
+		if part == "" {
+			continue
+		}
+		runes := []rune(part)
+		b.WriteRune(unicode.ToUpper(runes[0]))
+		b.WriteString(string(runes[1:]))
+	}
+	return b.String()
+}
+
+// inflectLast applies inflect to the lower-cased last word of name and restores its case
+func inflectLast(name string, inflect func(word string) string) string {
+	start := lastWordStart(name)
+	word := name[start:]
+	if word == "" {
+		return name
+	}
+
+	lower := strings.ToLower(word)
+	mu.RLock()
+	defer mu.RUnlock()
+	if uncountable[lower] {
+		return name
+	}
+	return name[:start] + matchCase(name, word, inflect(lower))
+}
+
+// lastWordStart the index where the last word of a snake_case or CamelCase name begins
+func lastWordStart(name string) int {
+	if i := strings.LastIndexAny(name, "_- "); i >= 0 {
+		return i + 1
+	}
+	runes := []rune(name)
+	offset := len(name)
+	for i := len(runes) - 1; i > 0; i-- {
+		offset -= len(string(runes[i]))
+		if unicode.IsUpper(runes[i]) && !unicode.IsUpper(runes[i-1]) {
+			return offset
+		}
+	}
+	return 0
+}
+
+// matchCase inflected in the case of word: a stem shared with word keeps word's
+// case, UserID -> UserIDs, anything else follows word's first letter or is
+// upper-cased when the whole name is
+func matchCase(name, word, inflected string) string {
+	lower := strings.ToLower(word)
+	switch {
+	case name == strings.ToUpper(name) && len(name) > 1:
+		return strings.ToUpper(inflected)
+	case strings.HasPrefix(inflected, lower):
+		return word + inflected[len(lower):]
+	case strings.HasPrefix(lower, inflected):
+		return word[:len(inflected)]
+	case unicode.IsUpper([]rune(word)[0]):
+		runes := []rune(inflected)
+		return string(unicode.ToUpper(runes[0])) + string(runes[1:])
+	}
+	return inflected
+}
+
+func isWord(word string) bool {
+	if word == "" {
+		return false
+	}
+	for _, r := range word {
+		if !unicode.IsLetter(r) {
+			return false
+		}
+	}
+	return true
+}
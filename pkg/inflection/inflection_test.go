@@ -0,0 +1,104 @@
+package inflection
+
+import (
+	"testing"
+
+	"gorm.io/gorm/schema"
+)
+
+func TestPluralSingular(t *testing.T) {
+	for singular, plural := range map[string]string{
+		// regular rules
+		"address":  "addresses",
+		"category": "categories",
+		"status":   "statuses",
+		"analysis": "analyses",
+		"user":     "users",
+		// irregular, human must not be read as hu-man
+		"person": "people",
+		"child":  "children",
+		"woman":  "women",
+		"human":  "humans",
+		// uncountable
+		"staff":    "staff",
+		"sheep":    "sheep",
+		"news":     "news",
+		"metadata": "metadata",
+		// the last word of a name, in its case
+		"order_item":  "order_items",
+		"OrderItem":   "OrderItems",
+		"UserID":      "UserIDs",
+		"Person":      "People",
+		"USER":        "USERS",
+		"team_person": "team_people",
+	} {
+		if got := Plural(singular); got != plural {
+			t.Errorf("Plural(%q) = %q, want %q", singular, got, plural)
+		}
+		if got := Singular(plural); got != singular {
+			t.Errorf("Singular(%q) = %q, want %q", plural, got, singular)
+		}
+	}
+}
+
+func TestTableAndModelName(t *testing.T) {
+	for model, table := range map[string]string{
+		"OrderItem": "order_items",
+		"Category":  "categories",
+		"Person":    "people",
+		"Staff":     "staff",
+		"Human":     "humans",
+	} {
+		if got := TableName(model); got != table {
+			t.Errorf("TableName(%q) = %q, want %q", model, got, table)
+		}
+		if got := ModelName(table); got != model {
+			t.Errorf("ModelName(%q) = %q, want %q", table, got, model)
+		}
+		// GORM names tables through github.com/jinzhu/inflection
+		if got := (schema.NamingStrategy{}).TableName(model); got != table {
+			t.Errorf("GORM table of %s = %q, want %q", model, got, table)
+		}
+	}
+
+	if got := Underscore("UserID"); got != "user_id" {
+		t.Errorf("Underscore(UserID) = %q, want user_id", got)
+	}
+	if got := Camelize("user_id"); got != "UserId" {
+		t.Errorf("Camelize(user_id) = %q, want UserId", got)
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	if err := Configure("cafe:cafez, gizmo"); err != nil {
+		t.Fatal(err)
+	}
+	if got := Plural("cafe"); got != "cafez" {
+		t.Errorf("Plural(cafe) = %q, want cafez", got)
+	}
+	if got := Singular("cafez"); got != "cafe" {
+		t.Errorf("Singular(cafez) = %q, want cafe", got)
+	}
+	if got := Plural("gizmo"); got != "gizmo" {
+		t.Errorf("Plural(gizmo) = %q, want gizmo", got)
+	}
+	for model, table := range map[string]string{"Cafe": "cafez", "Gizmo": "gizmo"} {
+		if got := (schema.NamingStrategy{}).TableName(model); got != table {
+			t.Errorf("GORM table of %s = %q, want %q", model, got, table)
+		}
+	}
+
+	// a later pair replaces the earlier one, in GORM too
+	if err := Configure("cafe:cafes"); err != nil {
+		t.Fatal(err)
+	}
+	if got := (schema.NamingStrategy{}).TableName("Cafe"); got != "cafes" {
+		t.Errorf("GORM table of Cafe = %q, want cafes", got)
+	}
+
+	for _, spec := range []string{"cafe:", ":cafes", "ca fe", "cafe:ca:fes", "caf3"} {
+		if err := Configure(spec); err == nil {
+			t.Errorf("Configure(%q) accepted an invalid inflection", spec)
+		}
+	}
+}
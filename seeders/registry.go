@@ -0,0 +1,5 @@
+package seeders
+
+var SeederRegistry = []Seeder{
+	{Name: "RolesSeeder", Run: RolesSeeder},
+}
@@ -0,0 +1,15 @@
+package seeders
+
+import (
+	"backends/internal/models"
+
+	"gorm.io/gorm"
+)
+
+func RolesSeeder(db *gorm.DB) error {
+	roles := []models.Role{
+		{Name: ptr("admin")},
+		{Name: ptr("user")},
+	}
+	return InsertMissing(db, &roles, "name")
+}
@@ -0,0 +1,41 @@
+package seeders
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Seeder struct {
+	Name string
+	Run  func(*gorm.DB) error
+}
+
+// Upsert inserts records, or updates the existing rows that collide on
+// conflictColumns. Without updateColumns every column is updated. On MySQL the
+// conflict is detected through the table's unique indexes.
+func Upsert(db *gorm.DB, records interface{}, conflictColumns []string, updateColumns ...string) error {
+	onConflict := clause.OnConflict{}
+	for _, column := range conflictColumns {
+		onConflict.Columns = append(onConflict.Columns, clause.Column{Name: column})
+	}
+	if len(updateColumns) == 0 {
+		onConflict.UpdateAll = true
+	} else {
+		onConflict.DoUpdates = clause.AssignmentColumns(updateColumns)
+	}
+	return db.Clauses(onConflict).Create(records).Error
+}
+
+// InsertMissing inserts records, leaving rows that already exist on conflictColumns untouched
+func InsertMissing(db *gorm.DB, records interface{}, conflictColumns ...string) error {
+	onConflict := clause.OnConflict{DoNothing: true}
+	for _, column := range conflictColumns {
+		onConflict.Columns = append(onConflict.Columns, clause.Column{Name: column})
+	}
+	return db.Clauses(onConflict).Create(records).Error
+}
+
+// ptr the address of v, for the nullable fields of generated models
+func ptr[T any](v T) *T {
+	return &v
+}